@@ -0,0 +1,112 @@
+package ts3
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+type ApiKeyScope string
+
+const (
+	ScopeManage ApiKeyScope = "manage"
+	ScopeWrite  ApiKeyScope = "write"
+	ScopeRead   ApiKeyScope = "read"
+)
+
+type ApiKey struct {
+	// The key itself, only returned when the key is created
+	Key    string      `json:"apikey"`
+	Id     int64       `json:"id,string"`
+	Sid    int64       `json:"sid,string"`
+	Cldbid int64       `json:"cldbid,string"`
+	Scope  ApiKeyScope `json:"scope"`
+	// Seconds until the key expires or "unlimited"
+	TimeLeft  string `json:"time_left"`
+	CreatedAt int64  `json:"created_at,string"`
+	ExpiresAt int64  `json:"expires_at,string"`
+}
+
+// Create an API key. Lifetime is in days (0 never expires) and cldbid is the user
+// the key acts as, 0 creates the key for the user that owns the current key
+func ApiKeyAdd(scope ApiKeyScope, lifetime int64, cldbid int64) (*status, *ApiKey, error) {
+	queries := []KeyValue{
+		{key: "scope", value: string(scope)},
+		{key: "lifetime", value: i64tostr(lifetime)},
+	}
+	if cldbid > 0 {
+		queries = append(queries, KeyValue{key: "cldbid", value: i64tostr(cldbid)})
+	}
+
+	qres, body, err := get("apikeyadd", false, queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to create a %v api key \n%v\n%v", scope, qres, err)
+		return qres, nil, err
+	}
+
+	var keys []ApiKey
+	json.Unmarshal([]byte(body), &keys)
+	if len(keys) == 0 {
+		return qres, nil, err
+	}
+
+	return qres, &keys[0], err
+}
+
+// List API keys belonging to a user, a cldbid of 0 lists the keys of every user
+func ApiKeyList(cldbid int64) (*status, []ApiKey, error) {
+	id := "*"
+	if cldbid > 0 {
+		id = i64tostr(cldbid)
+	}
+
+	queries := []KeyValue{
+		{key: "cldbid", value: id},
+	}
+
+	qres, body, err := get("apikeylist", false, queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to list api keys \n%v\n%v", qres, err)
+		return qres, nil, err
+	}
+
+	var keys []ApiKey
+	json.Unmarshal([]byte(body), &keys)
+	return qres, keys, err
+}
+
+// Delete an API key using the id from ApiKeyList
+func ApiKeyDel(id int64) (*status, error) {
+	queries := []KeyValue{
+		{key: "id", value: i64tostr(id)},
+	}
+
+	qres, _, err := get("apikeydel", false, queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to delete api key %v \n%v\n%v", id, qres, err)
+	}
+
+	return qres, err
+}
+
+// Mint a short lived key with a narrower scope using the current (manage) key
+// The configured key is left alone, pass the minted key to the job explicitly with QueryAs
+// or ConfigureHttp in the job's own process. Delete it with ApiKeyDel when the job is done
+//
+//	qres, key, err := ts3.ApiKeyMint(ts3.ScopeRead, 1)
+//	if err != nil || !qres.IsSuccess() {
+//		// handle error
+//	}
+//	defer ts3.ApiKeyDel(key.Id)
+//	qres, servers, err := ts3.QueryAs(key.Key, "serverlist")
+func ApiKeyMint(scope ApiKeyScope, lifetime int64) (*status, *ApiKey, error) {
+	if lifetime <= 0 {
+		return nil, nil, errors.New("minted api keys must have a lifetime")
+	}
+
+	qres, key, err := ApiKeyAdd(scope, lifetime, 0)
+	if err == nil && qres.IsSuccess() && key == nil {
+		return qres, nil, errors.New("apikeyadd returned no key")
+	}
+
+	return qres, key, err
+}
@@ -0,0 +1,194 @@
+package ts3
+
+import (
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type AuditEventType string
+
+const (
+	AuditClientConnected    AuditEventType = "client_connected"
+	AuditClientDisconnected AuditEventType = "client_disconnected"
+	AuditServerGroupAdded   AuditEventType = "servergroup_added"
+	AuditServerGroupRemoved AuditEventType = "servergroup_removed"
+	AuditChannelCreated     AuditEventType = "channel_created"
+	AuditChannelDeleted     AuditEventType = "channel_deleted"
+	AuditBanAdded           AuditEventType = "ban_added"
+	AuditPermissionChanged  AuditEventType = "permission_changed"
+)
+
+// A name and ID as written in the log, e.g. 'Sam'(id:2)
+// Client IDs in the log are database IDs (CLDBIDs)
+type AuditRef struct {
+	Name string
+	Id   int64
+}
+
+// A well known log message parsed into its parts, fields that don't apply to the event type are left empty
+type AuditEvent struct {
+	Type AuditEventType
+	// The client the event happened to
+	Client AuditRef
+	// The client who made the change
+	Invoker AuditRef
+	// The server group for group changes, or the group/channel/client a permission was changed on
+	Target AuditRef
+	// "servergroup", "channelgroup", "channel" or "client" for permission changes
+	TargetType string
+	// The channel created or deleted, Parent is set for sub channels
+	Channel AuditRef
+	Parent  AuditRef
+	// The permission changed and how ("added", "changed" or "deleted")
+	Permission      AuditRef
+	PermissionValue int64
+	Change          string
+	// IP of a connecting client or banned address
+	Ip string
+	// Disconnect or ban reason
+	Reason string
+	// Other ban details such as cluid, name and bantime
+	Details map[string]string
+	Entry   LogEntry
+}
+
+// 'name'(id:123)
+const auditRef = `'(.*?)'\(id:(\d+)\)`
+
+var (
+	auditConnected    = regexp.MustCompile(`^client connected ` + auditRef + ` from (\S+)`)
+	auditDisconnected = regexp.MustCompile(`^client disconnected ` + auditRef + ` reason '(.*)'`)
+	auditGroupAdded   = regexp.MustCompile(`^client ` + auditRef + ` was added to servergroup ` + auditRef + ` by client ` + auditRef)
+	auditGroupRemoved = regexp.MustCompile(`^client ` + auditRef + ` was removed from servergroup ` + auditRef + ` by client ` + auditRef)
+	auditChannel      = regexp.MustCompile(`^channel ` + auditRef + ` (created|deleted)(?: as sub channel of ` + auditRef + `)? by (?:client )?` + auditRef)
+	auditBan          = regexp.MustCompile(`^ban added (.*) by client ` + auditRef)
+	auditBanField     = regexp.MustCompile(`(\w+)='(.*?)'|(\w+)=(\S+)`)
+	auditPermission   = regexp.MustCompile(`^permission ` + auditRef + ` with values \(value:(-?\d+), negated:\d, skipchannel:\d\) was (added|changed|deleted|removed) by (?:client )?` + auditRef + ` (?:to|from|on|of) (servergroup|channelgroup|channel|client) ` + auditRef)
+)
+
+// Parse a log entry into an audit event, returns false if the message isn't a known audit message
+func ParseAuditEvent(entry LogEntry) (*AuditEvent, bool) {
+	msg := entry.Message
+	event := &AuditEvent{Entry: entry}
+
+	if m := auditConnected.FindStringSubmatch(msg); m != nil {
+		event.Type = AuditClientConnected
+		event.Client = auditRefOf(m[1], m[2])
+		event.Ip = stripPort(m[3])
+		return event, true
+	}
+
+	if m := auditDisconnected.FindStringSubmatch(msg); m != nil {
+		event.Type = AuditClientDisconnected
+		event.Client = auditRefOf(m[1], m[2])
+		event.Reason = strings.TrimPrefix(m[3], "reasonmsg=")
+		return event, true
+	}
+
+	if m := auditGroupAdded.FindStringSubmatch(msg); m != nil {
+		event.Type = AuditServerGroupAdded
+		event.Client, event.Target, event.Invoker = auditRefOf(m[1], m[2]), auditRefOf(m[3], m[4]), auditRefOf(m[5], m[6])
+		event.TargetType = "servergroup"
+		return event, true
+	}
+
+	if m := auditGroupRemoved.FindStringSubmatch(msg); m != nil {
+		event.Type = AuditServerGroupRemoved
+		event.Client, event.Target, event.Invoker = auditRefOf(m[1], m[2]), auditRefOf(m[3], m[4]), auditRefOf(m[5], m[6])
+		event.TargetType = "servergroup"
+		return event, true
+	}
+
+	if m := auditChannel.FindStringSubmatch(msg); m != nil {
+		event.Type = AuditChannelCreated
+		if m[3] == "deleted" {
+			event.Type = AuditChannelDeleted
+		}
+		event.Channel = auditRefOf(m[1], m[2])
+		if m[4] != "" {
+			event.Parent = auditRefOf(m[4], m[5])
+		}
+		event.Invoker = auditRefOf(m[6], m[7])
+		return event, true
+	}
+
+	if m := auditBan.FindStringSubmatch(msg); m != nil {
+		event.Type = AuditBanAdded
+		event.Invoker = auditRefOf(m[2], m[3])
+		event.Details = make(map[string]string)
+		for _, f := range auditBanField.FindAllStringSubmatch(m[1], -1) {
+			if f[1] != "" {
+				event.Details[f[1]] = f[2]
+			} else {
+				event.Details[f[3]] = f[4]
+			}
+		}
+		event.Reason = event.Details["reason"]
+		event.Ip = event.Details["ip"]
+		return event, true
+	}
+
+	if m := auditPermission.FindStringSubmatch(msg); m != nil {
+		event.Type = AuditPermissionChanged
+		event.Permission = auditRefOf(m[1], m[2])
+		event.PermissionValue, _ = strconv.ParseInt(m[3], 10, 64)
+		event.Change = m[4]
+		event.Invoker = auditRefOf(m[5], m[6])
+		event.TargetType = m[7]
+		event.Target = auditRefOf(m[8], m[9])
+		return event, true
+	}
+
+	return nil, false
+}
+
+// Parse the audit events out of a list of log entries, other entries are skipped
+func AuditEvents(entries []LogEntry) []AuditEvent {
+	events := []AuditEvent{}
+	for _, entry := range entries {
+		if event, ok := ParseAuditEvent(entry); ok {
+			events = append(events, *event)
+		}
+	}
+
+	return events
+}
+
+// Read the whole virtual server log and return its audit events
+func AuditLog() (*status, []AuditEvent, error) {
+	qres, entries, err := LogViewAll(false)
+	if err != nil || !qres.IsSuccess() {
+		return qres, nil, err
+	}
+
+	return qres, AuditEvents(entries), err
+}
+
+// The server group changes made to a client, e.g. to find who made them an admin and when
+func AuditServerGroupChanges(events []AuditEvent, cldbid int64) []AuditEvent {
+	changes := []AuditEvent{}
+	for _, event := range events {
+		if (event.Type == AuditServerGroupAdded || event.Type == AuditServerGroupRemoved) && event.Client.Id == cldbid {
+			changes = append(changes, event)
+		}
+	}
+
+	return changes
+}
+
+func auditRefOf(name string, id string) AuditRef {
+	i, _ := strconv.ParseInt(id, 10, 64)
+	return AuditRef{Name: name, Id: i}
+}
+
+// Remove the port from an ip:port pair
+func stripPort(address string) string {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return address
+	}
+
+	return host
+}
@@ -0,0 +1,155 @@
+package ts3
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAuditEvent(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		event AuditEvent
+	}{
+		{
+			name: "client connected",
+			line: "2020-06-20 12:00:00.123456|INFO    |VirtualServerBase|1  |client connected 'Sam'(id:2) from 203.0.113.7:52000",
+			event: AuditEvent{
+				Type:   AuditClientConnected,
+				Client: AuditRef{Name: "Sam", Id: 2},
+				Ip:     "203.0.113.7",
+			},
+		},
+		{
+			name: "client connected over ipv6",
+			line: "2020-06-20 12:00:00.123456|INFO    |VirtualServerBase|1  |client connected 'Sam'(id:2) from [2001:db8::1]:52000",
+			event: AuditEvent{
+				Type:   AuditClientConnected,
+				Client: AuditRef{Name: "Sam", Id: 2},
+				Ip:     "2001:db8::1",
+			},
+		},
+		{
+			name: "client disconnected",
+			line: "2020-06-20 12:30:00.000001|INFO    |VirtualServerBase|1  |client disconnected 'Sam'(id:2) reason 'reasonmsg=leaving'",
+			event: AuditEvent{
+				Type:   AuditClientDisconnected,
+				Client: AuditRef{Name: "Sam", Id: 2},
+				Reason: "leaving",
+			},
+		},
+		{
+			name: "server group added",
+			line: "2020-06-20 12:05:00.000000|INFO    |VirtualServer |1  |client 'X'(id:2) was added to servergroup 'Admin'(id:6) by client 'serveradmin'(id:1)",
+			event: AuditEvent{
+				Type:       AuditServerGroupAdded,
+				Client:     AuditRef{Name: "X", Id: 2},
+				Target:     AuditRef{Name: "Admin", Id: 6},
+				TargetType: "servergroup",
+				Invoker:    AuditRef{Name: "serveradmin", Id: 1},
+			},
+		},
+		{
+			name: "server group removed",
+			line: "2020-06-20 12:06:00.000000|INFO    |VirtualServer |1  |client 'X'(id:2) was removed from servergroup 'Admin'(id:6) by client 'serveradmin'(id:1)",
+			event: AuditEvent{
+				Type:       AuditServerGroupRemoved,
+				Client:     AuditRef{Name: "X", Id: 2},
+				Target:     AuditRef{Name: "Admin", Id: 6},
+				TargetType: "servergroup",
+				Invoker:    AuditRef{Name: "serveradmin", Id: 1},
+			},
+		},
+		{
+			name: "channel created",
+			line: "2020-06-20 12:10:00.000000|INFO    |VirtualServerBase|1  |channel 'Ops'(id:7) created by 'Sam'(id:2)",
+			event: AuditEvent{
+				Type:    AuditChannelCreated,
+				Channel: AuditRef{Name: "Ops", Id: 7},
+				Invoker: AuditRef{Name: "Sam", Id: 2},
+			},
+		},
+		{
+			name: "sub channel created",
+			line: "2020-06-20 12:11:00.000000|INFO    |VirtualServerBase|1  |channel 'Fleet 1'(id:8) created as sub channel of 'Ops'(id:7) by 'Sam'(id:2)",
+			event: AuditEvent{
+				Type:    AuditChannelCreated,
+				Channel: AuditRef{Name: "Fleet 1", Id: 8},
+				Parent:  AuditRef{Name: "Ops", Id: 7},
+				Invoker: AuditRef{Name: "Sam", Id: 2},
+			},
+		},
+		{
+			name: "channel deleted",
+			line: "2020-06-20 12:12:00.000000|INFO    |VirtualServerBase|1  |channel 'Ops'(id:7) deleted by 'Sam'(id:2)",
+			event: AuditEvent{
+				Type:    AuditChannelDeleted,
+				Channel: AuditRef{Name: "Ops", Id: 7},
+				Invoker: AuditRef{Name: "Sam", Id: 2},
+			},
+		},
+		{
+			name: "ban added",
+			line: "2020-06-20 12:20:00.000000|INFO    |VirtualServer |1  |ban added reason='spamming' cluid='abc123=' bantime=3600 by client 'serveradmin'(id:1)",
+			event: AuditEvent{
+				Type:    AuditBanAdded,
+				Invoker: AuditRef{Name: "serveradmin", Id: 1},
+				Reason:  "spamming",
+				Details: map[string]string{"reason": "spamming", "cluid": "abc123=", "bantime": "3600"},
+			},
+		},
+		{
+			name: "permission added",
+			line: "2020-06-20 12:25:00.000000|INFO    |VirtualServer |1  |permission 'b_client_kick_from_server'(id:169) with values (value:1, negated:0, skipchannel:0) was added by 'serveradmin'(id:1) to servergroup 'Admin'(id:6)",
+			event: AuditEvent{
+				Type:            AuditPermissionChanged,
+				Permission:      AuditRef{Name: "b_client_kick_from_server", Id: 169},
+				PermissionValue: 1,
+				Change:          "added",
+				Invoker:         AuditRef{Name: "serveradmin", Id: 1},
+				TargetType:      "servergroup",
+				Target:          AuditRef{Name: "Admin", Id: 6},
+			},
+		},
+		{
+			name: "permission changed on a channel",
+			line: "2020-06-20 12:26:00.000000|INFO    |VirtualServer |1  |permission 'i_channel_needed_talk_power'(id:110) with values (value:-1, negated:0, skipchannel:0) was changed by 'Sam'(id:2) on channel 'Ops'(id:7)",
+			event: AuditEvent{
+				Type:            AuditPermissionChanged,
+				Permission:      AuditRef{Name: "i_channel_needed_talk_power", Id: 110},
+				PermissionValue: -1,
+				Change:          "changed",
+				Invoker:         AuditRef{Name: "Sam", Id: 2},
+				TargetType:      "channel",
+				Target:          AuditRef{Name: "Ops", Id: 7},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := ParseLogLine(tt.line)
+			event, ok := ParseAuditEvent(entry)
+			if !ok {
+				t.Fatalf("ParseAuditEvent(%q) did not match", entry.Message)
+			}
+
+			tt.event.Entry = entry
+			if !reflect.DeepEqual(*event, tt.event) {
+				t.Errorf("ParseAuditEvent(%q)\ngot  %+v\nwant %+v", entry.Message, *event, tt.event)
+			}
+		})
+	}
+}
+
+func TestParseAuditEventUnknown(t *testing.T) {
+	for _, line := range []string{
+		"2020-06-20 12:00:00.000000|INFO    |ServerMain    |   |TeamSpeak 3 Server 3.13.3 (2020-12-16 14:17:05)",
+		"2020-06-20 12:00:00.000000|INFO    |Query         |   |query client connected 'serveradmin'(id:1)",
+		"not a log line",
+	} {
+		if event, ok := ParseAuditEvent(ParseLogLine(line)); ok {
+			t.Errorf("ParseAuditEvent(%q) = %+v, want no match", line, event)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package ts3
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Number of requests ServerGroupPoke and ChannelGroupPoke run at the same time
+const defaultBulkConcurrency = 4
+
+// An action run against a single target (a CLID or CLDBID depending on the action)
+type BulkAction func(target int64) (*status, error)
+
+// The outcome of running a BulkAction against one target
+type BulkTargetResult struct {
+	Target  int64
+	Success bool
+	Status  *status
+	Err     error
+}
+
+// The outcome of running a BulkAction against every target, Results is in the same order as the targets
+type BulkResult struct {
+	Results   []BulkTargetResult
+	Succeeded int
+	Failed    int
+}
+
+// Run an action against every target with at most concurrency actions running at once
+// Combine with ConfigureRateLimit to stay under the query flood limits
+func Bulk(targets []int64, concurrency int, action BulkAction) *BulkResult {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	result := &BulkResult{Results: make([]BulkTargetResult, len(targets))}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, target := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int, target int64) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			qres, err := action(target)
+			if err == nil && qres != nil && !qres.IsSuccess() {
+				err = fmt.Errorf("%v (code %v)", qres.Message, qres.Code)
+			}
+
+			result.Results[i] = BulkTargetResult{Target: target, Success: err == nil, Status: qres, Err: err}
+		}(i, target)
+	}
+	wg.Wait()
+
+	for _, r := range result.Results {
+		if r.Success {
+			result.Succeeded++
+		} else {
+			result.Failed++
+		}
+	}
+
+	return result
+}
+
+// Poke each target CLID
+func BulkPoke(msg string) BulkAction {
+	return func(clid int64) (*status, error) {
+		return UserPoke(clid, msg)
+	}
+}
+
+// Send a private text message to each target CLID
+func BulkMessage(msg string) BulkAction {
+	return func(clid int64) (*status, error) {
+		return UserMessage(clid, msg)
+	}
+}
+
+// Kick each target CLID from the server
+func BulkKick(msg string) BulkAction {
+	return func(clid int64) (*status, error) {
+		return UserKick(clid, msg)
+	}
+}
+
+// Move each target CLID into a channel
+func BulkMove(cid int64) BulkAction {
+	return func(clid int64) (*status, error) {
+		return UserMove(clid, cid)
+	}
+}
+
+// Add each target CLDBID to a server group
+func BulkAddServerGroup(sgid int64) BulkAction {
+	return func(cldbid int64) (*status, error) {
+		return ServerGroupsAddClient(sgid, cldbid)
+	}
+}
+
+// Collect the active session IDs (CLIDs) of a list of users
+func sessionIds(users []User) []int64 {
+	clids := []int64{}
+	for _, user := range users {
+		clids = append(clids, user.ActiveSessionIds...)
+	}
+
+	return clids
+}
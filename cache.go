@@ -0,0 +1,141 @@
+package ts3
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Read commands that are cleared from the cache when a mutating command succeeds
+var cacheInvalidations = map[string][]string{
+	"servergroupadd":        {"servergrouplist"},
+	"servergroupcopy":       {"servergrouplist"},
+	"servergroupdel":        {"servergrouplist", "servergroupclientlist", "servergroupsbyclientid"},
+	"servergrouprename":     {"servergrouplist"},
+	"servergroupaddclient":  {"servergroupclientlist", "servergroupsbyclientid"},
+	"servergroupdelclient":  {"servergroupclientlist", "servergroupsbyclientid"},
+	"channelgroupadd":       {"channelgrouplist"},
+	"channelgroupcopy":      {"channelgrouplist"},
+	"channelgroupdel":       {"channelgrouplist", "channelgroupclientlist"},
+	"channelgrouprename":    {"channelgrouplist"},
+	"setclientchannelgroup": {"channelgroupclientlist"},
+	"servercreate":          {"serverlist"},
+	"serverdelete":          {"serverlist"},
+	"serverstart":           {"serverlist"},
+	"serverstop":            {"serverlist"},
+	"serveredit":            {"serverlist", "serverinfo"},
+	"serversnapshotdeploy":  {"serverlist", "serverinfo", "servergrouplist", "channelgrouplist"},
+	"clientdbdelete":        {"clientdblist", "clientdbinfo", "clientdbfind", "servergroupclientlist", "channelgroupclientlist"},
+	"channeladdperm":        {"channelinfo", "channelpermlist"},
+	"channeldelperm":        {"channelinfo", "channelpermlist"},
+	"channelclientaddperm":  {"channelclientpermlist"},
+	"channelclientdelperm":  {"channelclientpermlist"},
+	"clientedit":            {"clientlist", "clientinfo"},
+	"tokenadd":              {"privilegekeylist"},
+	"privilegekeydelete":    {"privilegekeylist"},
+}
+
+type cacheEntry struct {
+	result  *Result
+	expires time.Time
+}
+
+var (
+	cacheMu   sync.Mutex
+	cacheTTLs map[string]time.Duration
+	cache     = make(map[string]cacheEntry)
+)
+
+// Cache the results of read commands, ttls maps a command (e.g. "servergrouplist") to how long it is cached for
+// Cached results are cleared when a command that changes them succeeds, pass nil to disable caching
+func ConfigureCache(ttls map[string]time.Duration) {
+	defer resetHandler()
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+
+	cacheTTLs = make(map[string]time.Duration)
+	for command, ttl := range ttls {
+		cacheTTLs[strings.ToLower(command)] = ttl
+	}
+	cache = make(map[string]cacheEntry)
+}
+
+// Clear cached results for the given commands, or everything if no commands are given
+func InvalidateCache(commands ...string) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+
+	if len(commands) == 0 {
+		cache = make(map[string]cacheEntry)
+		return
+	}
+
+	invalidate(commands)
+}
+
+// Remove every entry for the commands, cacheMu must be held
+func invalidate(commands []string) {
+	for key := range cache {
+		for _, command := range commands {
+			if strings.HasPrefix(key, strings.ToLower(command)+" ") {
+				delete(cache, key)
+			}
+		}
+	}
+}
+
+// Wraps a handler so cached results are returned instead of sending the request
+func cached(next Handler) Handler {
+	cacheMu.Lock()
+	enabled := len(cacheTTLs) > 0
+	cacheMu.Unlock()
+
+	if !enabled {
+		return next
+	}
+
+	return func(req *Request) *Result {
+		start := time.Now()
+		command := strings.ToLower(req.Command)
+		// The key includes the virtual server and API key so results are never shared between them
+		key := command + " " + req.ApiKey + " " + req.Http.URL.String()
+
+		cacheMu.Lock()
+		ttl, cacheable := cacheTTLs[command]
+		if entry, ok := cache[key]; ok && cacheable && time.Now().Before(entry.expires) {
+			cacheMu.Unlock()
+
+			res := copyResult(entry.result)
+			res.Cached = true
+			res.Duration = time.Since(start)
+			return res
+		}
+		cacheMu.Unlock()
+
+		res := next(req)
+		if res.Err != nil || res.Status == nil || !res.Status.IsSuccess() {
+			return res
+		}
+
+		cacheMu.Lock()
+		defer cacheMu.Unlock()
+
+		if cacheable && req.Http.Method == "GET" {
+			// Store a copy so the caller can't change the cached status
+			cache[key] = cacheEntry{result: copyResult(res), expires: time.Now().Add(ttl)}
+		}
+		if commands, ok := cacheInvalidations[command]; ok {
+			invalidate(commands)
+		}
+
+		return res
+	}
+}
+
+// Copy a result and its status so callers never share the cached status
+func copyResult(r *Result) *Result {
+	res := *r
+	status := *r.Status
+	res.Status = &status
+	return &res
+}
@@ -2,7 +2,6 @@ package ts3
 
 import (
 	"encoding/json"
-	"fmt"
 )
 
 type ChannelGroup struct {
@@ -62,6 +61,12 @@ func ResetChannelGroup(cid int64, cldbid int64) (*status, error) {
 
 // Return the members of a specific channel group for a given channel
 func ChannelGroupMembers(cgid int64, cid int64) (*status, []User, error) {
+	return ChannelGroupMembersWithOptions(cgid, cid, MemberOptions{})
+}
+
+// Return the members of a specific channel group for a given channel
+// The group is resolved in a constant number of requests regardless of its size
+func ChannelGroupMembersWithOptions(cgid int64, cid int64, opts MemberOptions) (*status, []User, error) {
 	queries := []KeyValue{
 		{key: "cid", value: i64tostr(cid)},
 		{key: "cgid", value: i64tostr(cgid)},
@@ -84,52 +89,29 @@ func ChannelGroupMembers(cgid int64, cid int64) (*status, []User, error) {
 	var cldbid []cldbid_
 	json.Unmarshal([]byte(body), &cldbid)
 
-	qres1, sessions, err := ActiveClients()
-	if err != nil {
-		return qres1, nil, err
-	}
-
-	// Build an array of Users with their active session IDs (CLIDs) included
-	groupmembers := []User{}
+	members := []User{}
 	for _, member := range cldbid {
-		_, u, err := UserFindByDbId(member.Clid)
-		if err != nil {
-			Log(Error, "Failed to look up cldbid %v \n%v", member.Clid, err)
-			continue
-		}
-
-		u.ActiveSessionIds = sessions[member.Clid]
+		members = append(members, User{Cldbid: member.Clid})
+	}
 
-		groupmembers = append(groupmembers, *u)
+	qres1, members, err := resolveMembers(members, opts)
+	if err != nil || (qres1 != nil && !qres1.IsSuccess()) {
+		Log(Error, "Failed to look up channelgroup %v members \n%v\n%v", cgid, qres1, err)
+		return qres1, nil, err
 	}
 
-	return qres, groupmembers, err
+	return qres, members, err
 }
 
 // Poke all clients who belong to a given channel group in a specific channel
-func ChannelGroupPoke(cgid int64, cid int64, msg string) (*status, error) {
+func ChannelGroupPoke(cgid int64, cid int64, msg string) (*status, *BulkResult, error) {
 	qres, members, err := ChannelGroupMembers(cgid, cid)
 	if err != nil || !qres.IsSuccess() {
 		Log(Error, "Failed to get group members")
-		return qres, err
-	}
-
-	attempted := 0
-	failed := 0
-
-	for _, user := range members {
-		for i := 0; i < len(user.ActiveSessionIds); i++ {
-			res, err := UserPoke(user.ActiveSessionIds[i], msg)
-			if err != nil {
-				failed++
-				Log(Error, "Failed to poke %v \n%v\n%v", user.Nickname, res, qres)
-			}
-
-			attempted++
-		}
+		return qres, nil, err
 	}
 
-	qres.Code = -1
-	qres.Message = fmt.Sprintf("%v%% of clients successfully poked (%v failed)", ((attempted-failed)/attempted)*100, failed)
-	return qres, err
+	result := Bulk(sessionIds(members), defaultBulkConcurrency, BulkPoke(msg))
+	Log(Notice, "Poked %v clients in channelgroup %v (%v failed)", result.Succeeded, cgid, result.Failed)
+	return qres, result, err
 }
@@ -0,0 +1,339 @@
+package ts3
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Which clients keep their voice when a channel is moderated
+type ModerationOptions struct {
+	// The talk power a client needs to speak in the channel while moderated
+	NeededTalkPower int64
+	// Clients in any of these server groups are made talkers
+	ExemptServerGroups []int64
+	// Clients holding any of these channel groups in the channel are made talkers
+	ExemptChannelGroups []int64
+	// Clients with these CLDBIDs are made talkers
+	ExemptUsers []int64
+}
+
+// A moderated channel, keep hold of it to Revert the channel later
+type ChannelModeration struct {
+	VirtualServer int64
+	ChannelId     int64
+	// Whether the channel had its own i_channel_needed_talk_power permission before, and its value
+	HadNeededTalkPower      bool
+	PreviousNeededTalkPower int64
+	NeededTalkPower         int64
+	// The CLIDs that were granted talker status
+	Talkers []int64
+	// The outcome of granting talker status to each exempt client
+	Result *BulkResult
+}
+
+// Set the talk power a client needs to speak in a channel
+func ChannelSetNeededTalkPower(cid int64, power int64) (*status, error) {
+	return channelSetNeededTalkPower(int64(virtualServer_), cid, power)
+}
+
+// Get the talk power a client needs to speak in a channel
+func ChannelNeededTalkPower(cid int64) (*status, int64, error) {
+	return channelNeededTalkPower(int64(virtualServer_), cid)
+}
+
+// Give a user a talk power in one channel only
+func ChannelClientSetTalkPower(cid int64, cldbid int64, power int64) (*status, error) {
+	queries := []KeyValue{
+		{key: "cid", value: i64tostr(cid)},
+		{key: "cldbid", value: i64tostr(cldbid)},
+		{key: "permsid", value: "i_client_talk_power"},
+		{key: "permvalue", value: i64tostr(power)},
+	}
+
+	qres, _, err := get("channelclientaddperm", false, queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to set the talk power of user %v in channel %v \n%v\n%v", cldbid, cid, qres, err)
+	}
+
+	return qres, err
+}
+
+// Remove a talk power given to a user with ChannelClientSetTalkPower
+func ChannelClientDelTalkPower(cid int64, cldbid int64) (*status, error) {
+	queries := []KeyValue{
+		{key: "cid", value: i64tostr(cid)},
+		{key: "cldbid", value: i64tostr(cldbid)},
+		{key: "permsid", value: "i_client_talk_power"},
+	}
+
+	qres, _, err := get("channelclientdelperm", false, queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to remove the talk power of user %v in channel %v \n%v\n%v", cldbid, cid, qres, err)
+	}
+
+	return qres, err
+}
+
+// Grant or revoke talker status for a session, talkers can speak regardless of talk power
+func UserSetTalker(clid int64, talker bool) (*status, error) {
+	return userSetTalker(int64(virtualServer_), clid, talker)
+}
+
+// Get the sessions connected to a channel including their groups and talker status
+func ChannelSessions(cid int64) (*status, []Session, error) {
+	return channelSessions(int64(virtualServer_), cid)
+}
+
+// Mute a channel for everyone except the exempt clients
+// The needed talk power is raised and exempt clients in the channel are made talkers
+// If no exempt client could be made a talker the channel is put back and an error returned
+// Clients who join after moderation starts are not made talkers
+func ChannelModerate(cid int64, opts ModerationOptions) (*status, *ChannelModeration, error) {
+	sid := int64(virtualServer_)
+
+	// Channels without any permissions of their own return an empty result set
+	qres, previous, had, err := channelNeededTalkPowerPerm(sid, cid)
+	if err != nil || (!qres.IsSuccess() && qres.Code != ErrCodeDatabaseEmptyResult) {
+		return qres, nil, err
+	}
+
+	qres, sessions, err := channelSessions(sid, cid)
+	if err != nil || !qres.IsSuccess() {
+		return qres, nil, err
+	}
+
+	qres, err = channelSetNeededTalkPower(sid, cid, opts.NeededTalkPower)
+	if err != nil || !qres.IsSuccess() {
+		return qres, nil, err
+	}
+
+	// Clients who are already talkers keep it after Revert
+	talkers := []int64{}
+	for _, session := range sessions {
+		if !bool(session.IsTalker) && opts.exempt(session) {
+			talkers = append(talkers, session.Clid)
+		}
+	}
+
+	moderation := &ChannelModeration{
+		VirtualServer:           sid,
+		ChannelId:               cid,
+		HadNeededTalkPower:      had,
+		PreviousNeededTalkPower: previous,
+		NeededTalkPower:         opts.NeededTalkPower,
+		Talkers:                 talkers,
+	}
+
+	moderation.Result = Bulk(talkers, defaultBulkConcurrency, func(clid int64) (*status, error) {
+		return userSetTalker(sid, clid, true)
+	})
+	// Nobody exempt can speak, don't leave the channel muted for everyone
+	if len(talkers) > 0 && moderation.Result.Succeeded == 0 {
+		Log(Error, "Failed to make any client a talker in channel %v, restoring its needed talk power", cid)
+		err = fmt.Errorf("failed to make any of the %v exempt clients a talker in channel %v", len(talkers), cid)
+
+		qres1, restoreErr := moderation.restoreNeededTalkPower()
+		if restoreErr == nil && !qres1.IsSuccess() {
+			restoreErr = fmt.Errorf("%v (code %v)", qres1.Message, qres1.Code)
+		}
+		if restoreErr != nil {
+			err = fmt.Errorf("%v, restoring the needed talk power also failed: %v", err, restoreErr)
+		}
+		return qres, moderation, err
+	}
+
+	Log(Notice, "Moderated channel %v, %v talkers (%v failed)", cid, moderation.Result.Succeeded, moderation.Result.Failed)
+	return qres, moderation, nil
+}
+
+// Restore the channel's needed talk power and revoke the talker status granted by ChannelModerate
+// Talkers who have since disconnected are reported as failures in the BulkResult
+func (m *ChannelModeration) Revert() (*status, *BulkResult, error) {
+	if m == nil {
+		return nil, nil, errors.New("no channel moderation to revert")
+	}
+
+	granted := []int64{}
+	if m.Result != nil {
+		for _, r := range m.Result.Results {
+			if r.Success {
+				granted = append(granted, r.Target)
+			}
+		}
+	}
+
+	result := Bulk(granted, defaultBulkConcurrency, func(clid int64) (*status, error) {
+		return userSetTalker(m.VirtualServer, clid, false)
+	})
+
+	qres, err := m.restoreNeededTalkPower()
+	if err != nil || !qres.IsSuccess() {
+		return qres, result, err
+	}
+
+	Log(Notice, "Reverted moderation of channel %v, %v talkers revoked (%v failed)", m.ChannelId, result.Succeeded, result.Failed)
+	return qres, result, nil
+}
+
+// Put the channel's needed talk power permission back the way it was, removing it if it wasn't set
+func (m *ChannelModeration) restoreNeededTalkPower() (*status, error) {
+	if m.HadNeededTalkPower {
+		return channelSetNeededTalkPower(m.VirtualServer, m.ChannelId, m.PreviousNeededTalkPower)
+	}
+
+	return channelDelNeededTalkPower(m.VirtualServer, m.ChannelId)
+}
+
+// Whether a session should keep its voice in a moderated channel
+func (o ModerationOptions) exempt(session Session) bool {
+	if session.Type != 0 {
+		return false
+	}
+
+	for _, cldbid := range o.ExemptUsers {
+		if session.Cldbid == cldbid {
+			return true
+		}
+	}
+
+	for _, cgid := range o.ExemptChannelGroups {
+		if session.ChannelGroupId == cgid {
+			return true
+		}
+	}
+
+	for _, sgid := range o.ExemptServerGroups {
+		for _, group := range session.ServerGroups {
+			if group == sgid {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func channelSetNeededTalkPower(sid int64, cid int64, power int64) (*status, error) {
+	queries := []KeyValue{
+		{key: "cid", value: i64tostr(cid)},
+		{key: "permsid", value: "i_channel_needed_talk_power"},
+		{key: "permvalue", value: i64tostr(power)},
+	}
+
+	qres, _, err := getSid(sid, "channeladdperm", queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to set the needed talk power of channel %v \n%v\n%v", cid, qres, err)
+	}
+
+	return qres, err
+}
+
+func channelDelNeededTalkPower(sid int64, cid int64) (*status, error) {
+	queries := []KeyValue{
+		{key: "cid", value: i64tostr(cid)},
+		{key: "permsid", value: "i_channel_needed_talk_power"},
+	}
+
+	qres, _, err := getSid(sid, "channeldelperm", queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to remove the needed talk power of channel %v \n%v\n%v", cid, qres, err)
+	}
+
+	return qres, err
+}
+
+// Read the channel's own i_channel_needed_talk_power permission, set is false if the channel doesn't have one
+func channelNeededTalkPowerPerm(sid int64, cid int64) (*status, int64, bool, error) {
+	queries := []KeyValue{
+		{key: "cid", value: i64tostr(cid)},
+		{key: "-permsid", value: ""},
+	}
+
+	qres, body, err := getSid(sid, "channelpermlist", queries)
+	if err == nil && qres.Code == ErrCodeDatabaseEmptyResult {
+		return qres, 0, false, nil
+	}
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to get the permissions of channel %v \n%v\n%v", cid, qres, err)
+		return qres, 0, false, err
+	}
+
+	type perm_ struct {
+		Permsid string `json:"permsid"`
+		Value   int64  `json:"permvalue,string"`
+	}
+
+	var perms []perm_
+	json.Unmarshal([]byte(body), &perms)
+	for _, perm := range perms {
+		if perm.Permsid == "i_channel_needed_talk_power" {
+			return qres, perm.Value, true, err
+		}
+	}
+
+	return qres, 0, false, err
+}
+
+func channelNeededTalkPower(sid int64, cid int64) (*status, int64, error) {
+	queries := []KeyValue{
+		{key: "cid", value: i64tostr(cid)},
+	}
+
+	qres, body, err := getSid(sid, "channelinfo", queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to get channel %v \n%v\n%v", cid, qres, err)
+		return qres, 0, err
+	}
+
+	type channel_ struct {
+		NeededTalkPower int64 `json:"channel_needed_talk_power,string"`
+	}
+
+	var channel []channel_
+	json.Unmarshal([]byte(body), &channel)
+	if len(channel) == 0 {
+		return qres, 0, fmt.Errorf("channel %v not found", cid)
+	}
+
+	return qres, channel[0].NeededTalkPower, err
+}
+
+func channelSessions(sid int64, cid int64) (*status, []Session, error) {
+	queries := []KeyValue{
+		{key: "-groups", value: ""},
+		{key: "-voice", value: ""},
+	}
+
+	qres, body, err := getSid(sid, "clientlist", queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to get the clients in channel %v \n%v\n%v", cid, qres, err)
+		return qres, nil, err
+	}
+
+	var sessions []Session
+	json.Unmarshal([]byte(body), &sessions)
+
+	inChannel := []Session{}
+	for _, session := range sessions {
+		if session.ChannelId == cid {
+			inChannel = append(inChannel, session)
+		}
+	}
+
+	return qres, inChannel, err
+}
+
+func userSetTalker(sid int64, clid int64, talker bool) (*status, error) {
+	queries := []KeyValue{
+		{key: "clid", value: i64tostr(clid)},
+		{key: "client_is_talker", value: booltostr(talker)},
+	}
+
+	qres, _, err := getSid(sid, "clientedit", queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to set talker status of client %v \n%v\n%v", clid, qres, err)
+	}
+
+	return qres, err
+}
@@ -0,0 +1,337 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	ts3 "github.com/samuelgrant/Teamspeak-GO"
+)
+
+func servers(action string, args []string) error {
+	switch action {
+	case "list":
+		qres, servers, err := ts3.ServersList()
+		if err := check(qres, err); err != nil {
+			return err
+		}
+
+		t := table{headers: []string{"id", "port", "status", "clients", "max clients", "uptime", "name"}, value: servers}
+		for _, s := range servers {
+			t.add(s.Id, s.Port, s.Status, s.ClientsOnline, s.MaxClients, s.Uptime, s.Name)
+		}
+		return t.print()
+
+	case "start", "stop":
+		if err := need(args, 1, "servers "+action+" <sid>"); err != nil {
+			return err
+		}
+		sid, err := id(args[0])
+		if err != nil {
+			return err
+		}
+
+		run := ts3.ServerStart
+		if action == "stop" {
+			run = ts3.ServerStop
+		}
+		if err := check(run(sid)); err != nil {
+			return err
+		}
+		return done("server %v: %v", sid, action)
+	}
+
+	return unknown("servers", action)
+}
+
+func groups(action string, args []string) error {
+	switch action {
+	case "list":
+		qres, groups, err := ts3.ServerGroups()
+		if err := check(qres, err); err != nil {
+			return err
+		}
+
+		t := table{headers: []string{"sgid", "type", "name"}, value: groups}
+		for _, g := range groups {
+			t.add(g.Id, g.Type, g.Name)
+		}
+		return t.print()
+
+	case "add":
+		if err := need(args, 1, "groups add <name>"); err != nil {
+			return err
+		}
+		qres, sgid, err := ts3.ServerGroupAdd(args[0])
+		if err := check(qres, err); err != nil {
+			return err
+		}
+
+		t := table{headers: []string{"sgid"}, value: map[string]int64{"sgid": sgid}}
+		t.add(sgid)
+		return t.print()
+
+	case "copy":
+		if err := need(args, 2, "groups copy <sgid> <name>"); err != nil {
+			return err
+		}
+		source, err := id(args[0])
+		if err != nil {
+			return err
+		}
+		qres, sgid, err := ts3.ServerGroupCopy(source, args[1])
+		if err := check(qres, err); err != nil {
+			return err
+		}
+
+		t := table{headers: []string{"sgid"}, value: map[string]int64{"sgid": sgid}}
+		t.add(sgid)
+		return t.print()
+
+	case "del":
+		flags := flag.NewFlagSet("groups del", flag.ExitOnError)
+		force := flags.Bool("force", false, "delete the group even if it has members")
+		positional := parseInterspersed(flags, args)
+		if err := need(positional, 1, "groups del <sgid> [-force]"); err != nil {
+			return err
+		}
+		sgid, err := id(positional[0])
+		if err != nil {
+			return err
+		}
+		if err := check(ts3.ServerGroupDel(sgid, *force)); err != nil {
+			return err
+		}
+		return done("deleted servergroup %v", sgid)
+
+	case "members":
+		if err := need(args, 1, "groups members <sgid>"); err != nil {
+			return err
+		}
+		sgid, err := id(args[0])
+		if err != nil {
+			return err
+		}
+		qres, members, err := ts3.ServerGroupMembers(sgid)
+		if err := check(qres, err); err != nil {
+			return err
+		}
+		return printUsers(members)
+
+	case "add-client", "revoke":
+		if err := need(args, 2, "groups "+action+" <sgid> <cldbid>"); err != nil {
+			return err
+		}
+		sgid, err := id(args[0])
+		if err != nil {
+			return err
+		}
+		cldbid, err := id(args[1])
+		if err != nil {
+			return err
+		}
+
+		run := ts3.ServerGroupsAddClient
+		if action == "revoke" {
+			run = ts3.ServerGroupsRevokeClient
+		}
+		if err := check(run(sgid, cldbid)); err != nil {
+			return err
+		}
+		return done("servergroup %v: %v cldbid %v", sgid, action, cldbid)
+	}
+
+	return unknown("groups", action)
+}
+
+func tokens(action string, args []string) error {
+	switch action {
+	case "add":
+		if len(args) < 2 {
+			return need(args, 2, "tokens add <sgid> <description> [ident=value ...]")
+		}
+		sgid, err := id(args[0])
+		if err != nil {
+			return err
+		}
+
+		custom := make(map[string]string)
+		for _, kv := range args[2:] {
+			parts := strings.SplitN(kv, "=", 2)
+			if len(parts) != 2 {
+				return fmt.Errorf("custom fields must be ident=value, got %q", kv)
+			}
+			custom[parts[0]] = parts[1]
+		}
+
+		qres, token, err := ts3.TokensAdd(sgid, args[1], custom)
+		if err := check(qres, err); err != nil {
+			return err
+		}
+
+		t := table{headers: []string{"token", "sgid", "description"}, value: token}
+		t.add(token.Token, token.GroupId, token.Description)
+		return t.print()
+
+	case "list":
+		qres, keys, err := ts3.TokensList()
+		if err := check(qres, err); err != nil {
+			return err
+		}
+
+		t := table{headers: []string{"token", "type", "group", "channel", "description"}, value: keys}
+		for _, k := range keys {
+			t.add(k.Token, k.Type, k.GroupId, k.ChannelId, k.Description)
+		}
+		return t.print()
+
+	case "delete":
+		if err := need(args, 1, "tokens delete <token>"); err != nil {
+			return err
+		}
+		if err := check(ts3.TokensDelete(args[0])); err != nil {
+			return err
+		}
+		return done("deleted token %v", args[0])
+	}
+
+	return unknown("tokens", action)
+}
+
+func users(action string, args []string) error {
+	switch action {
+	case "find":
+		return findUsers(args)
+
+	case "delete":
+		if err := need(args, 1, "users delete <cldbid>"); err != nil {
+			return err
+		}
+		cldbid, err := id(args[0])
+		if err != nil {
+			return err
+		}
+		if err := check(ts3.UserDelete(cldbid)); err != nil {
+			return err
+		}
+		return done("deleted cldbid %v", cldbid)
+
+	case "kick":
+		if len(args) < 1 || len(args) > 2 {
+			return need(args, 1, "users kick <cldbid> [message]")
+		}
+		cldbid, err := id(args[0])
+		if err != nil {
+			return err
+		}
+		msg := ""
+		if len(args) == 2 {
+			msg = args[1]
+		}
+		if err := check(ts3.UserKickClients(cldbid, msg)); err != nil {
+			return err
+		}
+		return done("kicked cldbid %v", cldbid)
+
+	case "poke":
+		if err := need(args, 2, "users poke <clid> <message>"); err != nil {
+			return err
+		}
+		clid, err := id(args[0])
+		if err != nil {
+			return err
+		}
+		if err := check(ts3.UserPoke(clid, args[1])); err != nil {
+			return err
+		}
+		return done("poked clid %v", clid)
+	}
+
+	return unknown("users", action)
+}
+
+// users find <cldbid> | -name <pattern> | -uid <uid> | -custom <ident> <pattern>
+func findUsers(args []string) error {
+	usage := "users find <cldbid> | find -name <pattern> | find -uid <uid> | find -custom <ident> <pattern>"
+	if len(args) == 0 {
+		return need(args, 1, usage)
+	}
+
+	switch args[0] {
+	case "-name", "-uid":
+		if err := need(args, 2, usage); err != nil {
+			return err
+		}
+
+		find := ts3.UserFindByName
+		if args[0] == "-uid" {
+			find = ts3.UserFindByUid
+		}
+		qres, users, err := find(args[1])
+		if err := check(qres, err); err != nil {
+			return err
+		}
+		return printUsers(users)
+
+	case "-custom":
+		if err := need(args, 3, usage); err != nil {
+			return err
+		}
+		qres, user, err := ts3.UserFindByCustomSearch(args[1], args[2])
+		if err := check(qres, err); err != nil {
+			return err
+		}
+		return printUsers([]ts3.User{*user})
+
+	default:
+		if err := need(args, 1, usage); err != nil {
+			return err
+		}
+		cldbid, err := id(args[0])
+		if err != nil {
+			return err
+		}
+		qres, found, err := ts3.UsersFindByDbIds([]int64{cldbid})
+		if err := check(qres, err); err != nil {
+			return err
+		}
+		return printUsers(found)
+	}
+}
+
+func printUsers(users []ts3.User) error {
+	t := table{headers: []string{"cldbid", "nickname", "uid", "last connected", "connections", "sessions"}, value: users}
+	for _, u := range users {
+		t.add(u.Cldbid, u.Nickname, u.Cluid, u.LastConnected, u.TotalConnections, u.ActiveSessionIds)
+	}
+
+	return t.print()
+}
+
+func message(action string, args []string) error {
+	switch action {
+	case "global":
+		if err := need(args, 1, "message global <message>"); err != nil {
+			return err
+		}
+		if err := check(ts3.ServerGlobalMessage(args[0])); err != nil {
+			return err
+		}
+		return done("message sent")
+
+	case "client":
+		if err := need(args, 2, "message client <clid> <message>"); err != nil {
+			return err
+		}
+		clid, err := id(args[0])
+		if err != nil {
+			return err
+		}
+		if err := check(ts3.UserMessage(clid, args[1])); err != nil {
+			return err
+		}
+		return done("message sent to clid %v", clid)
+	}
+
+	return unknown("message", action)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// Connection details read from the config file and environment
+type config struct {
+	Host   string `json:"host"`
+	ApiKey string `json:"api_key"`
+	Https  bool   `json:"https"`
+	Sid    int    `json:"sid"`
+}
+
+// The config file used when neither -config or TS3_CONFIG is set
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "ts3", "config.json")
+}
+
+// Load the config file (if it exists) and then apply environment variable overrides
+//
+//	TS3_HOST     WebQuery address, e.g. localhost:10080
+//	TS3_API_KEY  API key
+//	TS3_HTTPS    use https when set to true or 1
+//	TS3_SID      virtual server id (defaults to 1)
+func loadConfig(path string) (*config, error) {
+	cfg := &config{Sid: 1}
+
+	explicit := path != ""
+	if !explicit {
+		path = os.Getenv("TS3_CONFIG")
+		explicit = path != ""
+	}
+	if path == "" {
+		path = defaultConfigPath()
+	}
+
+	if path != "" {
+		data, err := ioutil.ReadFile(path)
+		switch {
+		case err == nil:
+			if err := json.Unmarshal(data, cfg); err != nil {
+				return nil, err
+			}
+		case explicit || !errors.Is(err, os.ErrNotExist):
+			return nil, err
+		}
+	}
+
+	if v := os.Getenv("TS3_HOST"); v != "" {
+		cfg.Host = v
+	}
+	if v := os.Getenv("TS3_API_KEY"); v != "" {
+		cfg.ApiKey = v
+	}
+	if v := os.Getenv("TS3_HTTPS"); v != "" {
+		https, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, errors.New("TS3_HTTPS must be true or false")
+		}
+		cfg.Https = https
+	}
+	if v := os.Getenv("TS3_SID"); v != "" {
+		sid, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, errors.New("TS3_SID must be a number")
+		}
+		cfg.Sid = sid
+	}
+
+	if cfg.Host == "" || cfg.ApiKey == "" {
+		return nil, errors.New("a host and api key are required, set TS3_HOST and TS3_API_KEY or use a config file")
+	}
+
+	return cfg, nil
+}
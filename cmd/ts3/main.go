@@ -0,0 +1,160 @@
+// Command ts3 administers a TeamSpeak server over the WebQuery
+//
+//	ts3 [-config file] [-o table|json|csv] [-sid id] [-v] <command> <action> [arguments]
+//
+// Connection details are read from a JSON config file and the TS3_HOST, TS3_API_KEY,
+// TS3_HTTPS and TS3_SID environment variables, see config.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	ts3 "github.com/samuelgrant/Teamspeak-GO"
+)
+
+const usage = `Usage: ts3 [flags] <command> <action> [arguments]
+
+Commands:
+  servers   list | start <sid> | stop <sid>
+  groups    list | add <name> | copy <sgid> <name> | del <sgid> [-force]
+            members <sgid> | add-client <sgid> <cldbid> | revoke <sgid> <cldbid>
+  tokens    add <sgid> <description> [ident=value ...] | list | delete <token>
+  users     find <cldbid> | find -name <pattern> | find -uid <uid> | find -custom <ident> <pattern>
+            delete <cldbid> | kick <cldbid> [message] | poke <clid> <message>
+  message   global <message> | client <clid> <message>
+  repl      interactive prompt for raw query commands
+
+Flags:
+`
+
+// The status returned by every library call
+type queryStatus interface {
+	IsSuccess() bool
+}
+
+func main() {
+	flags := flag.NewFlagSet("ts3", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flags.PrintDefaults()
+	}
+
+	configPath := flags.String("config", "", "path to a JSON config file (default $TS3_CONFIG or "+defaultConfigPath()+")")
+	output := flags.String("o", formatTable, "output format: table, json or csv")
+	sid := flags.Int("sid", 0, "virtual server id, overrides the config")
+	verbose := flags.Bool("v", false, "log the queries being executed")
+	flags.Parse(os.Args[1:])
+
+	switch *output {
+	case formatTable, formatJSON, formatCSV:
+		format = *output
+	default:
+		fail(fmt.Errorf("unknown output format %q", *output))
+	}
+
+	args := flags.Args()
+	if len(args) < 2 && !(len(args) == 1 && args[0] == "repl") {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		fail(err)
+	}
+	if *sid > 0 {
+		cfg.Sid = *sid
+	}
+
+	ts3.LoggingEnabled(*verbose)
+	ts3.ConfigureHttp(cfg.ApiKey, cfg.Host, cfg.Https)
+	ts3.SelectVirtualServer(cfg.Sid)
+
+	if args[0] == "repl" {
+		if err := repl(cfg.Sid); err != nil {
+			fail(err)
+		}
+		return
+	}
+
+	commands := map[string]func(action string, args []string) error{
+		"servers": servers,
+		"groups":  groups,
+		"tokens":  tokens,
+		"users":   users,
+		"message": message,
+	}
+
+	command, ok := commands[args[0]]
+	if !ok {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	if err := command(args[1], args[2:]); err != nil {
+		fail(err)
+	}
+}
+
+// Print an error and exit
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "ts3: %v\n", err)
+	os.Exit(1)
+}
+
+// Turn a failed query into an error
+func check(qres queryStatus, err error) error {
+	if err != nil {
+		return err
+	}
+	if !qres.IsSuccess() {
+		return fmt.Errorf("query failed %+v", qres)
+	}
+
+	return nil
+}
+
+// Require exactly n arguments
+// Parse flags that appear before, between or after the positional arguments
+// flag.Parse stops at the first positional argument, so "del 6 -force" would ignore -force
+func parseInterspersed(flags *flag.FlagSet, args []string) []string {
+	positional := []string{}
+	flags.Parse(args)
+	for flags.NArg() > 0 {
+		positional = append(positional, flags.Arg(0))
+		flags.Parse(flags.Args()[1:])
+	}
+
+	return positional
+}
+
+func need(args []string, n int, usage string) error {
+	if len(args) != n {
+		return fmt.Errorf("usage: ts3 %v", usage)
+	}
+
+	return nil
+}
+
+// Parse an ID argument
+func id(arg string) (int64, error) {
+	i, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a valid id", arg)
+	}
+
+	return i, nil
+}
+
+// Print a one line confirmation
+func done(format string, a ...interface{}) error {
+	fmt.Fprintf(os.Stderr, format+"\n", a...)
+	return nil
+}
+
+func unknown(command, action string) error {
+	return fmt.Errorf("unknown action %q for %v, run ts3 -h for help", action, command)
+}
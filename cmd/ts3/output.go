@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// Output formats selected with -o
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatCSV   = "csv"
+)
+
+var format = formatTable
+
+// Rows of a command's output, value is what gets encoded in the json output
+type table struct {
+	headers []string
+	rows    [][]string
+	value   interface{}
+}
+
+func (t *table) add(cells ...interface{}) {
+	row := []string{}
+	for _, c := range cells {
+		row = append(row, fmt.Sprint(c))
+	}
+
+	t.rows = append(t.rows, row)
+}
+
+// Write the table to stdout in the selected format
+func (t *table) print() error {
+	switch format {
+	case formatJSON:
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(t.value)
+	case formatCSV:
+		w := csv.NewWriter(os.Stdout)
+		w.Write(t.headers)
+		w.WriteAll(t.rows)
+		return w.Error()
+	default:
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, strings.ToUpper(strings.Join(t.headers, "\t")))
+		for _, row := range t.rows {
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+		return w.Flush()
+	}
+}
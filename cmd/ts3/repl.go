@@ -0,0 +1,204 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/peterh/liner"
+	ts3 "github.com/samuelgrant/Teamspeak-GO"
+)
+
+const replHelp = `Type any ServerQuery command, e.g. clientdbfind pattern=abc123= -uid
+Values containing spaces can be quoted: gm msg="hello world"
+  use <sid>   select a virtual server
+  help        show this message
+  exit        leave the repl (or Ctrl+D)
+`
+
+// Run an interactive prompt that sends raw query commands
+func repl(sid int) error {
+	line := liner.NewLiner()
+	defer line.Close()
+
+	line.SetCtrlCAborts(true)
+	line.SetCompleter(complete)
+
+	history := historyPath()
+	if f, err := os.Open(history); err == nil {
+		line.ReadHistory(f)
+		f.Close()
+	}
+	defer saveHistory(line, history)
+
+	fmt.Fprint(os.Stderr, replHelp)
+	for {
+		input, err := line.Prompt(fmt.Sprintf("ts3:%v> ", sid))
+		if err == io.EOF || err == liner.ErrPromptAborted {
+			fmt.Fprintln(os.Stderr)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		input = strings.TrimSpace(input)
+		if input == "" {
+			continue
+		}
+		line.AppendHistory(input)
+
+		words, err := splitArgs(input)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			continue
+		}
+
+		switch words[0] {
+		case "exit", "quit":
+			return nil
+		case "help":
+			fmt.Fprint(os.Stderr, replHelp)
+			continue
+		case "use":
+			if len(words) != 2 {
+				fmt.Fprintln(os.Stderr, "usage: use <sid>")
+				continue
+			}
+			next, err := strconv.Atoi(words[1])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error: %q is not a valid sid\n", words[1])
+				continue
+			}
+			sid = next
+			ts3.SelectVirtualServer(sid)
+			continue
+		}
+
+		qres, items, err := ts3.Query(words[0], words[1:]...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			continue
+		}
+		if !qres.IsSuccess() {
+			fmt.Fprintf(os.Stderr, "error id=%v msg=%v\n", qres.Code, qres.Message)
+			continue
+		}
+
+		printItems(items)
+	}
+}
+
+// Complete the command name from the query manual
+func complete(input string) []string {
+	if strings.Contains(input, " ") {
+		return nil
+	}
+
+	matches := []string{}
+	for _, c := range append(ts3.QueryCommands, "use", "help", "exit") {
+		if strings.HasPrefix(c, strings.ToLower(input)) {
+			matches = append(matches, c)
+		}
+	}
+
+	return matches
+}
+
+// Split a line into words, double quotes group words containing spaces
+func splitArgs(input string) ([]string, error) {
+	words := []string{}
+	var word strings.Builder
+	quoted, started := false, false
+
+	for _, r := range input {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			started = true
+		case r == ' ' && !quoted:
+			if started {
+				words = append(words, word.String())
+				word.Reset()
+				started = false
+			}
+		default:
+			word.WriteRune(r)
+			started = true
+		}
+	}
+
+	if quoted {
+		return nil, errors.New("unterminated quote")
+	}
+	if started {
+		words = append(words, word.String())
+	}
+
+	return words, nil
+}
+
+// Pretty print a query response, one block of aligned key/value pairs per item
+func printItems(items []map[string]string) {
+	if format == formatJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(items)
+		return
+	}
+
+	if len(items) == 0 {
+		fmt.Println("ok")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for i, item := range items {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+
+		keys := []string{}
+		for k := range item {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			fmt.Fprintf(w, "%v\t%v\n", k, item[k])
+		}
+	}
+	w.Flush()
+}
+
+// The history file lives next to the default config file
+func historyPath() string {
+	config := defaultConfigPath()
+	if config == "" {
+		return ""
+	}
+
+	return filepath.Join(filepath.Dir(config), "history")
+}
+
+func saveHistory(line *liner.State, path string) {
+	if path == "" {
+		return
+	}
+
+	os.MkdirAll(filepath.Dir(path), 0700)
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	line.WriteHistory(f)
+}
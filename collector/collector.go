@@ -0,0 +1,237 @@
+// Package collector exports TeamSpeak server statistics as Prometheus metrics
+//
+//	prometheus.MustRegister(collector.New(5 * time.Second))
+//	http.Handle("/metrics", promhttp.Handler())
+package collector
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ts3 "github.com/samuelgrant/Teamspeak-GO"
+)
+
+const namespace = "ts3"
+
+var (
+	serverLabels = []string{"sid", "name"}
+	groupLabels  = []string{"sid", "sgid", "group"}
+)
+
+// Collector implements prometheus.Collector for every virtual server returned by ts3.ServersList
+// Create it with New, the ts3 package must be configured with ts3.ConfigureHttp before the first scrape
+type Collector struct {
+	// A scrape that takes longer than this is abandoned and reported as failed
+	Timeout time.Duration
+
+	// Held from the start of a scrape until its requests finish, even when the scrape is abandoned
+	// A Collect that finds a scrape still running reports failure rather than stacking up requests
+	inFlight chan struct{}
+
+	up                  *prometheus.Desc
+	scrapeSuccess       *prometheus.Desc
+	scrapeDuration      *prometheus.Desc
+	serverUp            *prometheus.Desc
+	clientsOnline       *prometheus.Desc
+	maxClients          *prometheus.Desc
+	uptime              *prometheus.Desc
+	channelsOnline      *prometheus.Desc
+	packetLoss          *prometheus.Desc
+	ping                *prometheus.Desc
+	bytesSent           *prometheus.Desc
+	bytesReceived       *prometheus.Desc
+	bandwidthSent       *prometheus.Desc
+	bandwidthReceived   *prometheus.Desc
+	ftBytesSent         *prometheus.Desc
+	ftBytesReceived     *prometheus.Desc
+	ftBandwidthSent     *prometheus.Desc
+	ftBandwidthReceived *prometheus.Desc
+	groupOnline         *prometheus.Desc
+}
+
+// Create a collector, a timeout of 0 never abandons a scrape
+func New(timeout time.Duration) *Collector {
+	desc := func(name, help string, labels []string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "", name), help, labels, nil)
+	}
+
+	return &Collector{
+		Timeout:  timeout,
+		inFlight: make(chan struct{}, 1),
+
+		up:                  desc("up", "Whether the TeamSpeak WebQuery could be reached", nil),
+		scrapeSuccess:       desc("scrape_success", "Whether the last scrape completed within the timeout", nil),
+		scrapeDuration:      desc("scrape_duration_seconds", "Time taken to scrape the TeamSpeak server", nil),
+		serverUp:            desc("server_up", "Whether the virtual server is online", serverLabels),
+		clientsOnline:       desc("server_clients_online", "Number of clients connected to the virtual server", serverLabels),
+		maxClients:          desc("server_max_clients", "Maximum number of clients allowed on the virtual server", serverLabels),
+		uptime:              desc("server_uptime_seconds", "Time since the virtual server was started", serverLabels),
+		channelsOnline:      desc("server_channels_online", "Number of channels on the virtual server", serverLabels),
+		packetLoss:          desc("server_packet_loss_ratio", "Average packet loss of all connected clients", serverLabels),
+		ping:                desc("server_ping_milliseconds", "Average ping of all connected clients", serverLabels),
+		bytesSent:           desc("server_sent_bytes_total", "Bytes sent by the virtual server", serverLabels),
+		bytesReceived:       desc("server_received_bytes_total", "Bytes received by the virtual server", serverLabels),
+		bandwidthSent:       desc("server_bandwidth_sent_bytes_per_second", "Bandwidth sent during the last second", serverLabels),
+		bandwidthReceived:   desc("server_bandwidth_received_bytes_per_second", "Bandwidth received during the last second", serverLabels),
+		ftBytesSent:         desc("server_filetransfer_sent_bytes_total", "Bytes sent by file transfers", serverLabels),
+		ftBytesReceived:     desc("server_filetransfer_received_bytes_total", "Bytes received by file transfers", serverLabels),
+		ftBandwidthSent:     desc("server_filetransfer_bandwidth_sent_bytes_per_second", "Current file transfer upload bandwidth", serverLabels),
+		ftBandwidthReceived: desc("server_filetransfer_bandwidth_received_bytes_per_second", "Current file transfer download bandwidth", serverLabels),
+		groupOnline:         desc("server_group_online_members", "Number of connected clients belonging to a server group", groupLabels),
+	}
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{
+		c.up, c.scrapeSuccess, c.scrapeDuration, c.serverUp, c.clientsOnline, c.maxClients,
+		c.uptime, c.channelsOnline, c.packetLoss, c.ping, c.bytesSent, c.bytesReceived,
+		c.bandwidthSent, c.bandwidthReceived, c.ftBytesSent, c.ftBytesReceived,
+		c.ftBandwidthSent, c.ftBandwidthReceived, c.groupOnline,
+	} {
+		ch <- d
+	}
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	start := time.Now()
+
+	select {
+	case c.inFlight <- struct{}{}:
+	default:
+		ts3.Log(ts3.Error, "Prometheus scrape skipped, the previous scrape is still running")
+		c.failed(ch, start)
+		return
+	}
+
+	// Buffered so an abandoned scrape can still finish and be garbage collected
+	done := make(chan []prometheus.Metric, 1)
+	go func() {
+		defer func() { <-c.inFlight }()
+		done <- c.scrape()
+	}()
+
+	var timeout <-chan time.Time
+	if c.Timeout > 0 {
+		timer := time.NewTimer(c.Timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case metrics := <-done:
+		for _, m := range metrics {
+			ch <- m
+		}
+	case <-timeout:
+		ts3.Log(ts3.Error, "Prometheus scrape timed out after %v", c.Timeout)
+		c.failed(ch, start)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.scrapeSuccess, prometheus.GaugeValue, 1)
+	ch <- prometheus.MustNewConstMetric(c.scrapeDuration, prometheus.GaugeValue, time.Since(start).Seconds())
+}
+
+// Report a scrape that timed out or could not start
+func (c *Collector) failed(ch chan<- prometheus.Metric, start time.Time) {
+	ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, 0)
+	ch <- prometheus.MustNewConstMetric(c.scrapeSuccess, prometheus.GaugeValue, 0)
+	ch <- prometheus.MustNewConstMetric(c.scrapeDuration, prometheus.GaugeValue, time.Since(start).Seconds())
+}
+
+// Query every virtual server and build the metrics
+func (c *Collector) scrape() []prometheus.Metric {
+	metrics := []prometheus.Metric{}
+	gauge := func(d *prometheus.Desc, v float64, labels ...string) {
+		metrics = append(metrics, prometheus.MustNewConstMetric(d, prometheus.GaugeValue, v, labels...))
+	}
+	counter := func(d *prometheus.Desc, v float64, labels ...string) {
+		metrics = append(metrics, prometheus.MustNewConstMetric(d, prometheus.CounterValue, v, labels...))
+	}
+
+	qres, servers, err := ts3.ServersList()
+	if err != nil || !qres.IsSuccess() {
+		gauge(c.up, 0)
+		return metrics
+	}
+	gauge(c.up, 1)
+
+	for _, server := range servers {
+		sid := strconv.FormatInt(server.Id, 10)
+		online := server.Status == "online"
+
+		gauge(c.serverUp, boolToFloat(online), sid, server.Name)
+		gauge(c.clientsOnline, float64(server.ClientsOnline), sid, server.Name)
+		gauge(c.maxClients, float64(server.MaxClients), sid, server.Name)
+		gauge(c.uptime, float64(server.Uptime), sid, server.Name)
+
+		// Offline servers reject server scoped commands
+		if !online {
+			continue
+		}
+
+		qres, info, err := ts3.ServerInfo(server.Id)
+		if err == nil && qres.IsSuccess() && info != nil {
+			gauge(c.channelsOnline, float64(info.ChannelsOnline), sid, server.Name)
+			gauge(c.packetLoss, info.TotalPacketLoss, sid, server.Name)
+			gauge(c.ping, info.TotalPing, sid, server.Name)
+			counter(c.bytesSent, float64(info.BytesSentTotal), sid, server.Name)
+			counter(c.bytesReceived, float64(info.BytesReceivedTotal), sid, server.Name)
+			gauge(c.bandwidthSent, float64(info.BandwidthSentLastSecond), sid, server.Name)
+			gauge(c.bandwidthReceived, float64(info.BandwidthReceivedLastSecond), sid, server.Name)
+			counter(c.ftBytesSent, float64(info.FileTransferBytesSentTotal), sid, server.Name)
+			counter(c.ftBytesReceived, float64(info.FileTransferBytesReceivedTotal), sid, server.Name)
+			gauge(c.ftBandwidthSent, float64(info.FileTransferBandwidthSent), sid, server.Name)
+			gauge(c.ftBandwidthReceived, float64(info.FileTransferBandwidthReceived), sid, server.Name)
+		}
+
+		metrics = append(metrics, c.groupMetrics(server.Id)...)
+	}
+
+	return metrics
+}
+
+// Count the connected voice clients in each server group
+func (c *Collector) groupMetrics(sid int64) []prometheus.Metric {
+	qres, groups, err := ts3.ServerGroupsBySid(sid)
+	if err != nil || !qres.IsSuccess() {
+		return nil
+	}
+
+	qres, sessions, err := ts3.ServerSessions(sid)
+	if err != nil || !qres.IsSuccess() {
+		return nil
+	}
+
+	online := make(map[int64]int)
+	for _, session := range sessions {
+		if session.Type != 0 {
+			continue
+		}
+
+		for _, sgid := range session.ServerGroups {
+			online[sgid]++
+		}
+	}
+
+	metrics := []prometheus.Metric{}
+	for _, group := range groups {
+		if group.Type != ts3.RegularGroup {
+			continue
+		}
+
+		metrics = append(metrics, prometheus.MustNewConstMetric(c.groupOnline, prometheus.GaugeValue,
+			float64(online[group.Id]), strconv.FormatInt(sid, 10), strconv.FormatInt(group.Id, 10), group.Name))
+	}
+
+	return metrics
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
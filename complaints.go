@@ -0,0 +1,136 @@
+package ts3
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+type Complaint struct {
+	// The user the complaint is about
+	TargetCldbid int64  `json:"tcldbid,string"`
+	TargetName   string `json:"tname"`
+	// The user who made the complaint
+	SourceCldbid int64  `json:"fcldbid,string"`
+	SourceName   string `json:"fname"`
+	Message      string `json:"message"`
+	Timestamp    int64  `json:"timestamp,string"`
+}
+
+// The complaints made about a single user
+type ComplaintSummary struct {
+	TargetCldbid int64
+	TargetName   string
+	Complaints   []Complaint
+	// Number of different users who complained
+	Sources int
+	// Unix timestamp of the newest complaint
+	Latest int64
+}
+
+// List complaints, a tcldbid of 0 lists the complaints about every user
+func ComplainList(tcldbid int64) (*status, []Complaint, error) {
+	queries := []KeyValue{}
+	if tcldbid > 0 {
+		queries = append(queries, KeyValue{key: "tcldbid", value: i64tostr(tcldbid)})
+	}
+
+	qres, body, err := get("complainlist", false, queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to get the complaint list \n%v\n%v", qres, err)
+		return qres, nil, err
+	}
+
+	var complaints []Complaint
+	json.Unmarshal([]byte(body), &complaints)
+	return qres, complaints, err
+}
+
+// Submit a complaint about a user
+func ComplainAdd(tcldbid int64, message string) (*status, error) {
+	queries := []KeyValue{
+		{key: "tcldbid", value: i64tostr(tcldbid)},
+		{key: "message", value: message},
+	}
+
+	qres, _, err := get("complainadd", false, queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to add a complaint about cldbid %v \n%v\n%v", tcldbid, qres, err)
+	}
+
+	return qres, err
+}
+
+// Delete the complaint fcldbid made about tcldbid
+func ComplainDel(tcldbid int64, fcldbid int64) (*status, error) {
+	queries := []KeyValue{
+		{key: "tcldbid", value: i64tostr(tcldbid)},
+		{key: "fcldbid", value: i64tostr(fcldbid)},
+	}
+
+	qres, _, err := get("complaindel", false, queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to delete complaint {tcldbid: %v, fcldbid: %v} \n%v\n%v", tcldbid, fcldbid, qres, err)
+	}
+
+	return qres, err
+}
+
+// Delete every complaint about a user
+func ComplainDelAll(tcldbid int64) (*status, error) {
+	queries := []KeyValue{
+		{key: "tcldbid", value: i64tostr(tcldbid)},
+	}
+
+	qres, _, err := get("complaindelall", false, queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to delete the complaints about cldbid %v \n%v\n%v", tcldbid, qres, err)
+	}
+
+	return qres, err
+}
+
+// Group every complaint on the server by the user it is about, users with the most complaints first
+func ComplaintsByUser() (*status, []ComplaintSummary, error) {
+	qres, complaints, err := ComplainList(0)
+	if err != nil || !qres.IsSuccess() {
+		return qres, nil, err
+	}
+
+	return qres, SummariseComplaints(complaints), err
+}
+
+// Group complaints by the user they are about, users with the most complaints first
+func SummariseComplaints(complaints []Complaint) []ComplaintSummary {
+	byUser := make(map[int64]*ComplaintSummary)
+	sources := make(map[int64]map[int64]bool)
+	order := []int64{}
+
+	for _, c := range complaints {
+		summary, ok := byUser[c.TargetCldbid]
+		if !ok {
+			summary = &ComplaintSummary{TargetCldbid: c.TargetCldbid, TargetName: c.TargetName}
+			byUser[c.TargetCldbid] = summary
+			sources[c.TargetCldbid] = make(map[int64]bool)
+			order = append(order, c.TargetCldbid)
+		}
+
+		summary.Complaints = append(summary.Complaints, c)
+		sources[c.TargetCldbid][c.SourceCldbid] = true
+		if c.Timestamp > summary.Latest {
+			summary.Latest = c.Timestamp
+		}
+	}
+
+	summaries := []ComplaintSummary{}
+	for _, cldbid := range order {
+		summary := byUser[cldbid]
+		summary.Sources = len(sources[cldbid])
+		summaries = append(summaries, *summary)
+	}
+
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return len(summaries[i].Complaints) > len(summaries[j].Complaints)
+	})
+
+	return summaries
+}
@@ -0,0 +1,386 @@
+package ts3
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// A file or directory stored in a channel (cid 0 holds icons and avatars)
+type File struct {
+	ChannelId int64  `json:"cid,string"`
+	Path      string `json:"path"`
+	Name      string `json:"name"`
+	Size      int64  `json:"size,string"`
+	Modified  int64  `json:"datetime,string"`
+	// 0 for directories, 1 for files
+	Type int64 `json:"type,string"`
+}
+
+// Called as a transfer progresses with the bytes transferred so far and the total size
+type ProgressFunc func(transferred int64, total int64)
+
+// A file transfer that has been set up with ftinitupload or ftinitdownload
+// Data is streamed over the file transfer port (30033 by default) using the ftkey handshake
+type FileTransfer struct {
+	ClientFtId int64  `json:"clientftfid,string"`
+	ServerFtId int64  `json:"serverftfid,string"`
+	Key        string `json:"ftkey"`
+	Port       int64  `json:"port,string"`
+	Ip         string `json:"ip"`
+	// The size of the file being downloaded, or for uploads the size being sent
+	Size int64 `json:"size,string"`
+	// Where an upload resumes from
+	SeekPos int64 `json:"seekpos,string"`
+
+	// Set when the server rejects the transfer, e.g. the file already exists
+	Status  int64  `json:"status,string"`
+	Message string `json:"msg"`
+
+	Progress ProgressFunc
+
+	mu      sync.Mutex
+	conn    net.Conn
+	stopped bool
+}
+
+// Client side transfer IDs only need to be unique per connection
+var clientFtId int64
+
+// List the files in a channel directory, cpw is the channel password and path starts with "/"
+func FileList(cid int64, cpw string, path string) (*status, []File, error) {
+	queries := []KeyValue{
+		{key: "cid", value: i64tostr(cid)},
+		{key: "cpw", value: cpw},
+		{key: "path", value: path},
+	}
+
+	qres, body, err := get("ftgetfilelist", false, queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to list files in channel %v %v \n%v\n%v", cid, path, qres, err)
+		return qres, nil, err
+	}
+
+	var files []File
+	json.Unmarshal([]byte(body), &files)
+	for i := range files {
+		files[i].Path = path
+	}
+
+	return qres, files, err
+}
+
+// Get information about a file, name is the full path e.g. "/docs/fleet.txt"
+func FileInfo(cid int64, cpw string, name string) (*status, *File, error) {
+	queries := []KeyValue{
+		{key: "cid", value: i64tostr(cid)},
+		{key: "cpw", value: cpw},
+		{key: "name", value: name},
+	}
+
+	qres, body, err := get("ftgetfileinfo", false, queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to get file info for %v in channel %v \n%v\n%v", name, cid, qres, err)
+		return qres, nil, err
+	}
+
+	var files []File
+	json.Unmarshal([]byte(body), &files)
+	if len(files) == 0 {
+		return qres, nil, err
+	}
+
+	return qres, &files[0], err
+}
+
+// Create a directory in a channel
+func FileCreateDir(cid int64, cpw string, dirname string) (*status, error) {
+	queries := []KeyValue{
+		{key: "cid", value: i64tostr(cid)},
+		{key: "cpw", value: cpw},
+		{key: "dirname", value: dirname},
+	}
+
+	qres, _, err := get("ftcreatedir", false, queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to create directory %v in channel %v \n%v\n%v", dirname, cid, qres, err)
+	}
+
+	return qres, err
+}
+
+// Delete a file or directory from a channel
+func FileDelete(cid int64, cpw string, name string) (*status, error) {
+	queries := []KeyValue{
+		{key: "cid", value: i64tostr(cid)},
+		{key: "cpw", value: cpw},
+		{key: "name", value: name},
+	}
+
+	qres, _, err := get("ftdeletefile", false, queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to delete %v from channel %v \n%v\n%v", name, cid, qres, err)
+	}
+
+	return qres, err
+}
+
+// Rename a file within a channel
+func FileRename(cid int64, cpw string, oldName string, newName string) (*status, error) {
+	queries := []KeyValue{
+		{key: "cid", value: i64tostr(cid)},
+		{key: "cpw", value: cpw},
+		{key: "oldname", value: oldName},
+		{key: "newname", value: newName},
+	}
+
+	qres, _, err := get("ftrenamefile", false, queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to rename %v to %v in channel %v \n%v\n%v", oldName, newName, cid, qres, err)
+	}
+
+	return qres, err
+}
+
+// Move a file to another channel, tcpw is the target channel's password
+func FileMove(cid int64, cpw string, oldName string, tcid int64, tcpw string, newName string) (*status, error) {
+	queries := []KeyValue{
+		{key: "cid", value: i64tostr(cid)},
+		{key: "cpw", value: cpw},
+		{key: "tcid", value: i64tostr(tcid)},
+		{key: "tcpw", value: tcpw},
+		{key: "oldname", value: oldName},
+		{key: "newname", value: newName},
+	}
+
+	qres, _, err := get("ftrenamefile", false, queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to move %v from channel %v to %v \n%v\n%v", oldName, cid, tcid, qres, err)
+	}
+
+	return qres, err
+}
+
+// Set up an upload of size bytes, call Upload on the result to send the data
+func FileInitUpload(cid int64, cpw string, name string, size int64, overwrite bool, resume bool) (*status, *FileTransfer, error) {
+	queries := []KeyValue{
+		{key: "clientftfid", value: i64tostr(atomic.AddInt64(&clientFtId, 1))},
+		{key: "name", value: name},
+		{key: "cid", value: i64tostr(cid)},
+		{key: "cpw", value: cpw},
+		{key: "size", value: i64tostr(size)},
+		{key: "overwrite", value: booltostr(overwrite)},
+		{key: "resume", value: booltostr(resume)},
+	}
+
+	qres, ft, err := initTransfer("ftinitupload", queries)
+	if ft != nil {
+		ft.Size = size
+	}
+
+	return qres, ft, err
+}
+
+// Set up a download, call Download on the result to receive the data
+func FileInitDownload(cid int64, cpw string, name string, seekpos int64) (*status, *FileTransfer, error) {
+	queries := []KeyValue{
+		{key: "clientftfid", value: i64tostr(atomic.AddInt64(&clientFtId, 1))},
+		{key: "name", value: name},
+		{key: "cid", value: i64tostr(cid)},
+		{key: "cpw", value: cpw},
+		{key: "seekpos", value: i64tostr(seekpos)},
+	}
+
+	qres, ft, err := initTransfer("ftinitdownload", queries)
+	if ft != nil {
+		ft.SeekPos = seekpos
+	}
+
+	return qres, ft, err
+}
+
+// Upload a file from r in one call, progress may be nil
+func FileUpload(cid int64, cpw string, name string, r io.Reader, size int64, overwrite bool, progress ProgressFunc) (*status, error) {
+	qres, ft, err := FileInitUpload(cid, cpw, name, size, overwrite, false)
+	if err != nil || !qres.IsSuccess() {
+		return qres, err
+	}
+
+	ft.Progress = progress
+	return qres, ft.Upload(r)
+}
+
+// Download a file into w in one call, progress may be nil
+func FileDownload(cid int64, cpw string, name string, w io.Writer, progress ProgressFunc) (*status, error) {
+	qres, ft, err := FileInitDownload(cid, cpw, name, 0)
+	if err != nil || !qres.IsSuccess() {
+		return qres, err
+	}
+
+	ft.Progress = progress
+	return qres, ft.Download(w)
+}
+
+// Send ftinitupload or ftinitdownload and check the transfer was accepted
+func initTransfer(command string, queries []KeyValue) (*status, *FileTransfer, error) {
+	qres, body, err := get(command, false, queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to start file transfer %v \n%v\n%v", command, qres, err)
+		return qres, nil, err
+	}
+
+	var transfers []*FileTransfer
+	json.Unmarshal([]byte(body), &transfers)
+	if len(transfers) == 0 {
+		return qres, nil, fmt.Errorf("%v returned no transfer", command)
+	}
+
+	ft := transfers[0]
+	if ft.Status != 0 {
+		Log(Error, "File transfer rejected \n%v (%v)", ft.Message, ft.Status)
+		return qres, nil, fmt.Errorf("file transfer rejected: %v (%v)", ft.Message, ft.Status)
+	}
+
+	return qres, ft, err
+}
+
+// Stream r to the server, stops after Size - SeekPos bytes
+// When resuming, r must already be positioned at SeekPos
+func (t *FileTransfer) Upload(r io.Reader) error {
+	conn, err := t.connect()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// A reader that runs out early would leave a truncated file on the server
+	n, err := t.copy(conn, io.LimitReader(r, t.Size-t.SeekPos), t.Size-t.SeekPos)
+	if err == nil && n < t.Size-t.SeekPos {
+		err = io.ErrUnexpectedEOF
+	}
+
+	return t.stoppedErr(err)
+}
+
+// Stream the file from the server into w
+func (t *FileTransfer) Download(w io.Writer) error {
+	conn, err := t.connect()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	n, err := t.copy(w, conn, t.Size-t.SeekPos)
+	if err == nil && n < t.Size-t.SeekPos {
+		err = io.ErrUnexpectedEOF
+	}
+
+	return t.stoppedErr(err)
+}
+
+// Cancel the transfer, deleteFile removes a partially uploaded file from the server
+func (t *FileTransfer) Stop(deleteFile bool) (*status, error) {
+	t.mu.Lock()
+	t.stopped = true
+	if t.conn != nil {
+		t.conn.Close()
+	}
+	t.mu.Unlock()
+
+	queries := []KeyValue{
+		{key: "serverftfid", value: i64tostr(t.ServerFtId)},
+		{key: "delete", value: booltostr(deleteFile)},
+	}
+
+	qres, _, err := get("ftstop", false, queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to stop file transfer %v \n%v\n%v", t.ServerFtId, qres, err)
+	}
+
+	return qres, err
+}
+
+// Open the file transfer connection and send the ftkey
+func (t *FileTransfer) connect() (net.Conn, error) {
+	conn, err := net.Dial("tcp", net.JoinHostPort(t.host(), i64tostr(t.Port)))
+	if err != nil {
+		Log(Error, "Failed to connect to the file transfer port \n%v", err)
+		return nil, err
+	}
+
+	t.mu.Lock()
+	if t.stopped {
+		t.mu.Unlock()
+		conn.Close()
+		return nil, errors.New("file transfer stopped")
+	}
+	t.conn = conn
+	t.mu.Unlock()
+
+	if _, err := io.WriteString(conn, t.Key); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// The server may tell us which address to use, otherwise use the WebQuery host
+func (t *FileTransfer) host() string {
+	for _, ip := range strings.Split(t.Ip, ",") {
+		if ip != "" && ip != "0.0.0.0" && ip != "::" {
+			return ip
+		}
+	}
+
+	host, _, err := net.SplitHostPort(baseUrl_)
+	if err != nil {
+		return baseUrl_
+	}
+
+	return host
+}
+
+// Copy data reporting progress after each chunk
+func (t *FileTransfer) copy(dst io.Writer, src io.Reader, total int64) (int64, error) {
+	buf := make([]byte, 32*1024)
+	var written int64
+
+	for {
+		n, err := src.Read(buf)
+		if n > 0 {
+			if _, werr := dst.Write(buf[:n]); werr != nil {
+				return written, werr
+			}
+
+			written += int64(n)
+			if t.Progress != nil {
+				t.Progress(t.SeekPos+written, t.Size)
+			}
+		}
+
+		if err == io.EOF || written >= total {
+			return written, nil
+		}
+		if err != nil {
+			return written, err
+		}
+	}
+}
+
+// Replace the error from a closed connection when the transfer was stopped on purpose
+func (t *FileTransfer) stoppedErr(err error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err != nil && t.stopped {
+		return errors.New("file transfer stopped")
+	}
+
+	return err
+}
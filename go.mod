@@ -3,5 +3,6 @@ module github.com/samuelgrant/Teamspeak-GO
 go 1.14
 
 require (
-
+	github.com/peterh/liner v1.2.2
+	github.com/prometheus/client_golang v1.9.0
 )
@@ -0,0 +1,111 @@
+package ts3
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// The identity the API key is acting as, returned by whoami
+type Identity struct {
+	VirtualServerStatus           string `json:"virtualserver_status"`
+	VirtualServerId               int64  `json:"virtualserver_id,string"`
+	VirtualServerUniqueIdentifier string `json:"virtualserver_unique_identifier"`
+	VirtualServerPort             int64  `json:"virtualserver_port,string"`
+	ClientId                      int64  `json:"client_id,string"`
+	ChannelId                     int64  `json:"client_channel_id,string"`
+	Nickname                      string `json:"client_nickname"`
+	Cldbid                        int64  `json:"client_database_id,string"`
+	LoginName                     string `json:"client_login_name"`
+	Cluid                         string `json:"client_unique_identifier"`
+	OriginServerId                int64  `json:"client_origin_server_id,string"`
+}
+
+// The result of a health check
+type Health struct {
+	// The WebQuery answered the request
+	Reachable bool `json:"reachable"`
+	// The request succeeded and the selected virtual server is online
+	Healthy             bool          `json:"healthy"`
+	Latency             time.Duration `json:"latency_ns"`
+	VirtualServerId     int           `json:"virtual_server_id"`
+	VirtualServerStatus string        `json:"virtual_server_status,omitempty"`
+	Identity            *Identity     `json:"identity,omitempty"`
+	Error               string        `json:"error,omitempty"`
+}
+
+// Get the identity of the API key on the selected virtual server
+func WhoAmI() (*status, *Identity, error) {
+	qres, body, err := get("whoami", false)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to get the current identity \n%v\n%v", qres, err)
+		return qres, nil, err
+	}
+
+	var identity []Identity
+	json.Unmarshal([]byte(body), &identity)
+	if len(identity) == 0 {
+		return qres, nil, err
+	}
+
+	return qres, &identity[0], err
+}
+
+// Time a whoami request to the selected virtual server
+func Ping() (time.Duration, error) {
+	start := time.Now()
+	qres, _, err := WhoAmI()
+	latency := time.Since(start)
+
+	if err == nil && !qres.IsSuccess() {
+		err = fmt.Errorf("%v (code %v)", qres.Message, qres.Code)
+	}
+
+	return latency, err
+}
+
+// Check the WebQuery can be reached, the API key works and the selected virtual server is online
+func HealthCheck() *Health {
+	health := &Health{VirtualServerId: virtualServer_}
+
+	start := time.Now()
+	qres, identity, err := WhoAmI()
+	health.Latency = time.Since(start)
+
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+
+	health.Reachable = true
+	if !qres.IsSuccess() {
+		health.Error = fmt.Sprintf("%v (code %v)", qres.Message, qres.Code)
+		return health
+	}
+
+	if identity != nil {
+		health.Identity = identity
+		health.VirtualServerStatus = identity.VirtualServerStatus
+	}
+	health.Healthy = health.VirtualServerStatus == "online"
+
+	return health
+}
+
+// An http.Handler for readiness probes and status pages
+// Responds 200 when healthy and 503 otherwise, with the Health as JSON
+func HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		health := HealthCheck()
+
+		w.Header().Set("Content-Type", "application/json")
+		if health.Healthy {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+
+		json.NewEncoder(w).Encode(health)
+	})
+}
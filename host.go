@@ -0,0 +1,202 @@
+package ts3
+
+import (
+	"encoding/json"
+)
+
+// Instance wide statistics returned by hostinfo
+type HostInfo struct {
+	Uptime               int64 `json:"instance_uptime,string"`
+	Timestamp            int64 `json:"host_timestamp_utc,string"`
+	ServersRunning       int64 `json:"virtualservers_running_total,string"`
+	TotalMaxClients      int64 `json:"virtualservers_total_maxclients,string"`
+	TotalClientsOnline   int64 `json:"virtualservers_total_clients_online,string"`
+	TotalChannelsOnline  int64 `json:"virtualservers_total_channels_online,string"`
+	PacketsSentTotal     int64 `json:"connection_packets_sent_total,string"`
+	PacketsReceivedTotal int64 `json:"connection_packets_received_total,string"`
+	BytesSentTotal       int64 `json:"connection_bytes_sent_total,string"`
+	BytesReceivedTotal   int64 `json:"connection_bytes_received_total,string"`
+
+	// Bandwidth in bytes per second
+	BandwidthSentLastSecond     int64 `json:"connection_bandwidth_sent_last_second_total,string"`
+	BandwidthReceivedLastSecond int64 `json:"connection_bandwidth_received_last_second_total,string"`
+	BandwidthSentLastMinute     int64 `json:"connection_bandwidth_sent_last_minute_total,string"`
+	BandwidthReceivedLastMinute int64 `json:"connection_bandwidth_received_last_minute_total,string"`
+
+	FileTransferBandwidthSent      int64 `json:"connection_filetransfer_bandwidth_sent,string"`
+	FileTransferBandwidthReceived  int64 `json:"connection_filetransfer_bandwidth_received,string"`
+	FileTransferBytesSentTotal     int64 `json:"connection_filetransfer_bytes_sent_total,string"`
+	FileTransferBytesReceivedTotal int64 `json:"connection_filetransfer_bytes_received_total,string"`
+}
+
+// Instance configuration returned by instanceinfo
+type InstanceInfo struct {
+	DatabaseVersion             int64 `json:"serverinstance_database_version,string"`
+	FileTransferPort            int64 `json:"serverinstance_filetransfer_port,string"`
+	MaxDownloadTotalBandwidth   int64 `json:"serverinstance_max_download_total_bandwidth,string"`
+	MaxUploadTotalBandwidth     int64 `json:"serverinstance_max_upload_total_bandwidth,string"`
+	GuestServerQueryGroup       int64 `json:"serverinstance_guest_serverquery_group,string"`
+	ServerQueryFloodCommands    int64 `json:"serverinstance_serverquery_flood_commands,string"`
+	ServerQueryFloodTime        int64 `json:"serverinstance_serverquery_flood_time,string"`
+	ServerQueryBanTime          int64 `json:"serverinstance_serverquery_ban_time,string"`
+	TemplateServerAdminGroup    int64 `json:"serverinstance_template_serveradmin_group,string"`
+	TemplateServerDefaultGroup  int64 `json:"serverinstance_template_serverdefault_group,string"`
+	TemplateChannelAdminGroup   int64 `json:"serverinstance_template_channeladmin_group,string"`
+	TemplateChannelDefaultGroup int64 `json:"serverinstance_template_channeldefault_group,string"`
+	PermissionsVersion          int64 `json:"serverinstance_permissions_version,string"`
+	PendingConnectionsPerIp     int64 `json:"serverinstance_pending_connections_per_ip,string"`
+}
+
+// Properties that can be changed using InstanceEdit, only fields that are not nil are sent
+type InstanceProperties struct {
+	GuestServerQueryGroup       *int64
+	FileTransferPort            *int64
+	MaxDownloadTotalBandwidth   *int64
+	MaxUploadTotalBandwidth     *int64
+	ServerQueryFloodCommands    *int64
+	ServerQueryFloodTime        *int64
+	ServerQueryBanTime          *int64
+	TemplateServerAdminGroup    *int64
+	TemplateServerDefaultGroup  *int64
+	TemplateChannelAdminGroup   *int64
+	TemplateChannelDefaultGroup *int64
+	PendingConnectionsPerIp     *int64
+}
+
+// Convert the properties that have been set into query parameters
+func (p InstanceProperties) queries() []KeyValue {
+	queries := []KeyValue{}
+
+	num := func(key string, v *int64) {
+		if v != nil {
+			queries = append(queries, KeyValue{key: key, value: i64tostr(*v)})
+		}
+	}
+
+	num("serverinstance_guest_serverquery_group", p.GuestServerQueryGroup)
+	num("serverinstance_filetransfer_port", p.FileTransferPort)
+	num("serverinstance_max_download_total_bandwidth", p.MaxDownloadTotalBandwidth)
+	num("serverinstance_max_upload_total_bandwidth", p.MaxUploadTotalBandwidth)
+	num("serverinstance_serverquery_flood_commands", p.ServerQueryFloodCommands)
+	num("serverinstance_serverquery_flood_time", p.ServerQueryFloodTime)
+	num("serverinstance_serverquery_ban_time", p.ServerQueryBanTime)
+	num("serverinstance_template_serveradmin_group", p.TemplateServerAdminGroup)
+	num("serverinstance_template_serverdefault_group", p.TemplateServerDefaultGroup)
+	num("serverinstance_template_channeladmin_group", p.TemplateChannelAdminGroup)
+	num("serverinstance_template_channeldefault_group", p.TemplateChannelDefaultGroup)
+	num("serverinstance_pending_connections_per_ip", p.PendingConnectionsPerIp)
+
+	return queries
+}
+
+// The server build returned by version
+type Version struct {
+	Version  string `json:"version"`
+	Build    int64  `json:"build,string"`
+	Platform string `json:"platform"`
+}
+
+// Get uptime, client totals and bandwidth counters for the whole instance
+func Host() (*status, *HostInfo, error) {
+	qres, body, err := get("hostinfo", true)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to get host information \n%v\n%v", qres, err)
+		return qres, nil, err
+	}
+
+	var info []HostInfo
+	json.Unmarshal([]byte(body), &info)
+	if len(info) == 0 {
+		return qres, nil, err
+	}
+
+	return qres, &info[0], err
+}
+
+// Get the instance configuration
+func Instance() (*status, *InstanceInfo, error) {
+	qres, body, err := get("instanceinfo", true)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to get instance information \n%v\n%v", qres, err)
+		return qres, nil, err
+	}
+
+	var info []InstanceInfo
+	json.Unmarshal([]byte(body), &info)
+	if len(info) == 0 {
+		return qres, nil, err
+	}
+
+	return qres, &info[0], err
+}
+
+// Change the instance configuration
+func InstanceEdit(props InstanceProperties) (*status, error) {
+	qres, _, err := get("instanceedit", true, props.queries())
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to edit the instance \n%v\n%v", qres, err)
+	}
+
+	return qres, err
+}
+
+// Get the version, build and platform of the server
+func ServerVersion() (*status, *Version, error) {
+	qres, body, err := get("version", true)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to get the server version \n%v\n%v", qres, err)
+		return qres, nil, err
+	}
+
+	var version []Version
+	json.Unmarshal([]byte(body), &version)
+	if len(version) == 0 {
+		return qres, nil, err
+	}
+
+	return qres, &version[0], err
+}
+
+// Shut down the server process, reason is shown to connected clients
+func ServerProcessStop(reason string) (*status, error) {
+	queries := []KeyValue{}
+	if reason != "" {
+		queries = append(queries, KeyValue{key: "reasonmsg", value: reason})
+	}
+
+	qres, _, err := get("serverprocessstop", true, queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to stop the server process \n%v\n%v", qres, err)
+	}
+
+	return qres, err
+}
+
+// List the IP addresses the instance is bound to
+// subsystem can be "voice", "query" or "filetransfer", an empty string defaults to voice
+func BindingList(subsystem string) (*status, []string, error) {
+	queries := []KeyValue{}
+	if subsystem != "" {
+		queries = append(queries, KeyValue{key: "subsystem", value: subsystem})
+	}
+
+	qres, body, err := get("bindinglist", true, queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to get the binding list \n%v\n%v", qres, err)
+		return qres, nil, err
+	}
+
+	type ip_ struct {
+		Ip string `json:"ip"`
+	}
+
+	var bindings []ip_
+	json.Unmarshal([]byte(body), &bindings)
+
+	ips := []string{}
+	for _, b := range bindings {
+		ips = append(ips, b.Ip)
+	}
+
+	return qres, ips, err
+}
@@ -0,0 +1,122 @@
+package ts3
+
+import (
+	"bytes"
+	"hash/crc32"
+	"strconv"
+	"strings"
+)
+
+// Icons are stored in the channel 0 file system as /icon_<id>
+const iconChannel int64 = 0
+
+// Calculate the icon ID TeamSpeak expects for an image, the CRC32 of its contents
+func IconId(data []byte) int64 {
+	return int64(crc32.ChecksumIEEE(data))
+}
+
+// Upload a PNG as an icon and return its ID, uploading an icon that already exists is not an error
+func IconUpload(png []byte) (*status, int64, error) {
+	id := IconId(png)
+
+	qres, err := FileUpload(iconChannel, "", iconName(id), bytes.NewReader(png), int64(len(png)), true, nil)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to upload icon %v \n%v\n%v", id, qres, err)
+		return qres, -1, err
+	}
+
+	return qres, id, err
+}
+
+// List the IDs of the icons uploaded to the virtual server
+func IconList() (*status, []int64, error) {
+	qres, files, err := FileList(iconChannel, "", "/icons/")
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to list icons \n%v\n%v", qres, err)
+		return qres, nil, err
+	}
+
+	ids := []int64{}
+	for _, file := range files {
+		id, err := strconv.ParseInt(strings.TrimPrefix(file.Name, "icon_"), 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	return qres, ids, err
+}
+
+// Delete an icon from the virtual server
+func IconDelete(id int64) (*status, error) {
+	return FileDelete(iconChannel, "", iconName(id))
+}
+
+// Set the icon shown next to a server group
+func IconSetServerGroup(sgid int64, id int64) (*status, error) {
+	queries := append([]KeyValue{{key: "sgid", value: i64tostr(sgid)}}, iconPermission(id)...)
+	queries = append(queries, KeyValue{key: "permnegated", value: "0"}, KeyValue{key: "permskip", value: "0"})
+
+	qres, _, err := get("servergroupaddperm", false, queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to set the icon for servergroup %v \n%v\n%v", sgid, qres, err)
+	}
+
+	return qres, err
+}
+
+// Set the icon shown next to a channel group
+func IconSetChannelGroup(cgid int64, id int64) (*status, error) {
+	queries := append([]KeyValue{{key: "cgid", value: i64tostr(cgid)}}, iconPermission(id)...)
+
+	qres, _, err := get("channelgroupaddperm", false, queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to set the icon for channelgroup %v \n%v\n%v", cgid, qres, err)
+	}
+
+	return qres, err
+}
+
+// Set the icon shown next to a channel
+func IconSetChannel(cid int64, id int64) (*status, error) {
+	queries := append([]KeyValue{{key: "cid", value: i64tostr(cid)}}, iconPermission(id)...)
+
+	qres, _, err := get("channeladdperm", false, queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to set the icon for channel %v \n%v\n%v", cid, qres, err)
+	}
+
+	return qres, err
+}
+
+// Set the icon of a virtual server
+func IconSetServer(sid int64, id int64) (*status, error) {
+	queries := []KeyValue{
+		{key: "virtualserver_icon_id", value: i64tostr(id)},
+	}
+
+	qres, _, err := getSid(sid, "serveredit", queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to set the icon for server %v \n%v\n%v", sid, qres, err)
+	}
+
+	return qres, err
+}
+
+func iconName(id int64) string {
+	return "/icon_" + i64tostr(id)
+}
+
+// Permission values are signed 32 bit integers, icon IDs above that wrap around to negative values
+func iconPermValue(id int64) int64 {
+	return int64(int32(uint32(id)))
+}
+
+// The i_icon_id permission parameters
+func iconPermission(id int64) []KeyValue {
+	return []KeyValue{
+		{key: "permsid", value: "i_icon_id"},
+		{key: "permvalue", value: i64tostr(iconPermValue(id))},
+	}
+}
@@ -3,6 +3,8 @@ package ts3
 import (
 	"fmt"
 	"log"
+	"net/url"
+	"strings"
 )
 
 type LogLevel int
@@ -21,7 +23,35 @@ const (
 	NoticeColour = "\033[1;36m%s\033[0m"
 )
 
-var logging bool = false
+var (
+	logging bool = false
+	logger_ StructuredLogger
+)
+
+// A levelled logger taking alternating key/value fields, *slog.Logger satisfies this interface
+type StructuredLogger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// Query parameters that are never written to the logs
+var sensitiveParams = map[string]bool{
+	"token":                  true,
+	"password":               true,
+	"client_login_password":  true,
+	"virtualserver_password": true,
+	"channel_password":       true,
+	"cpw":                    true,
+	"tcpw":                   true,
+	"apikey":                 true,
+	"ftkey":                  true,
+	"salt":                   true,
+	"data":                   true,
+}
+
+// Query parameters that are attached to request logs as fields
+var logFields = []string{"cldbid", "clid", "cid", "sgid", "cgid"}
 
 // Allow you to turn logging on or off
 func LoggingEnabled(enable bool) {
@@ -34,17 +64,110 @@ func LoggingEnabled(enable bool) {
 	logging = enable
 }
 
+// Send logs to a structured logger such as a *slog.Logger instead of the coloured standard logger
+// Setting a logger enables logging, pass nil to go back to the coloured output
+func SetLogger(l StructuredLogger) {
+	logger_ = l
+	logging = l != nil || logging
+}
+
 func Log(loglevel LogLevel, format string, a ...interface{}) {
-	if logging {
-		switch loglevel {
-		case Debug:
-			log.Printf(DebugColour, fmt.Sprintf("[debug]: "+format, a...))
-		case Notice:
-			log.Printf(NoticeColour, fmt.Sprintf("[notice]: "+format, a...))
-		case Error:
-			log.Printf(ErrorColour, fmt.Sprintf("[error]: "+format, a...))
-		case CmdExc:
-			log.Printf(CmdColour, fmt.Sprintf("[executed]: "+format, a...))
+	if !logging {
+		return
+	}
+
+	if logger_ != nil {
+		structuredLog(loglevel, fmt.Sprintf(format, a...))
+		return
+	}
+
+	switch loglevel {
+	case Debug:
+		log.Printf(DebugColour, fmt.Sprintf("[debug]: "+format, a...))
+	case Notice:
+		log.Printf(NoticeColour, fmt.Sprintf("[notice]: "+format, a...))
+	case Error:
+		log.Printf(ErrorColour, fmt.Sprintf("[error]: "+format, a...))
+	case CmdExc:
+		log.Printf(CmdColour, fmt.Sprintf("[executed]: "+format, a...))
+	}
+}
+
+// Log a message with key/value fields, the coloured output appends them as key=value
+func logAttrs(loglevel LogLevel, msg string, args ...interface{}) {
+	if !logging {
+		return
+	}
+
+	if logger_ != nil {
+		structuredLog(loglevel, msg, args...)
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString(msg)
+	for i := 0; i+1 < len(args); i += 2 {
+		fmt.Fprintf(&b, " %v=%v", args[i], args[i+1])
+	}
+
+	Log(loglevel, "%v", b.String())
+}
+
+// Log the outcome of a request with the command, virtual server and status as fields
+func logRequest(req *Request, res *Result) {
+	args := []interface{}{
+		"command", req.Command,
+		"sid", req.VirtualServer,
+		"url", redactUrl(req.Http.URL),
+		"http_status", res.HttpStatus,
+		"duration", res.Duration,
+	}
+
+	query := req.Http.URL.Query()
+	for _, field := range logFields {
+		if v := query.Get(field); v != "" {
+			args = append(args, field, v)
 		}
 	}
+
+	if res.Status != nil {
+		args = append(args, "code", res.Status.Code, "message", res.Status.Message)
+	}
+	if res.Err != nil {
+		args = append(args, "error", res.Err)
+	}
+
+	logAttrs(CmdExc, "query executed", args...)
+}
+
+// Returns the URL with sensitive query parameters replaced
+func redactUrl(u *url.URL) string {
+	redacted := *u
+
+	parts := strings.Split(u.RawQuery, "&")
+	for i, part := range parts {
+		kv := strings.SplitN(part, "=", 2)
+		key, err := url.QueryUnescape(kv[0])
+		if err != nil || len(kv) != 2 || !sensitiveParams[strings.ToLower(key)] {
+			continue
+		}
+
+		parts[i] = kv[0] + "=REDACTED"
+	}
+	redacted.RawQuery = strings.Join(parts, "&")
+	redacted.User = nil
+
+	return redacted.String()
+}
+
+// Map the library log levels onto the structured logger's levels
+func structuredLog(loglevel LogLevel, msg string, args ...interface{}) {
+	switch loglevel {
+	case Error:
+		logger_.Error(msg, args...)
+	case Notice:
+		logger_.Info(msg, args...)
+	default:
+		logger_.Debug(msg, args...)
+	}
 }
@@ -0,0 +1,250 @@
+package ts3
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A parsed server log line, e.g.
+// 2020-06-20 12:00:00.123456|INFO    |VirtualServerBase|1  |client connected 'Sam'(id:2) from 127.0.0.1:52000
+type LogEntry struct {
+	Time    time.Time
+	Level   string
+	Channel string
+	// The virtual server the entry belongs to, 0 for instance entries
+	ServerId int64
+	Message  string
+	Raw      string
+	// Byte offset of the line in the log file, only set by LogView
+	Pos int64
+}
+
+type LogViewOptions struct {
+	// Number of lines to return, between 1 and 100 (defaults to 100)
+	Lines int
+	// Return the page newest line first
+	Reverse bool
+	// Read the instance log instead of the virtual server log
+	Instance bool
+	// Return the lines before this byte offset, use LastPos from the previous page
+	// 0 returns the newest lines in the log
+	BeginPos int64
+}
+
+// One page of log entries returned by logview
+// logview reads backwards, each page holds the lines before the previous one
+type LogPage struct {
+	Entries []LogEntry
+	// Byte offset of the oldest line in the page, 0 once the start of the log is reached
+	LastPos int64
+	// Size of the log file in bytes
+	FileSize int64
+}
+
+const logTimeFormat = "2006-01-02 15:04:05.999999"
+
+// Read a page of the server log
+func LogView(opts LogViewOptions) (*status, *LogPage, error) {
+	if opts.Lines <= 0 || opts.Lines > 100 {
+		opts.Lines = 100
+	}
+
+	queries := []KeyValue{
+		{key: "lines", value: strconv.Itoa(opts.Lines)},
+		{key: "reverse", value: booltostr(opts.Reverse)},
+		{key: "instance", value: booltostr(opts.Instance)},
+	}
+	if opts.BeginPos > 0 {
+		queries = append(queries, KeyValue{key: "begin_pos", value: i64tostr(opts.BeginPos)})
+	}
+
+	qres, body, err := get("logview", false, queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to read the server log \n%v\n%v", qres, err)
+		return qres, nil, err
+	}
+
+	type line_ struct {
+		LastPos  string `json:"last_pos"`
+		FileSize string `json:"file_size"`
+		Line     string `json:"l"`
+	}
+
+	var lines []line_
+	json.Unmarshal([]byte(body), &lines)
+
+	page := &LogPage{Entries: []LogEntry{}}
+	for i, l := range lines {
+		if i == 0 {
+			page.LastPos, _ = strconv.ParseInt(l.LastPos, 10, 64)
+			page.FileSize, _ = strconv.ParseInt(l.FileSize, 10, 64)
+		}
+
+		if l.Line != "" {
+			page.Entries = append(page.Entries, ParseLogLine(l.Line))
+		}
+	}
+
+	// Work out where each line starts, counting forward from the oldest line
+	pos := page.LastPos
+	for i := range page.Entries {
+		entry := &page.Entries[i]
+		if opts.Reverse {
+			entry = &page.Entries[len(page.Entries)-1-i]
+		}
+
+		entry.Pos = pos
+		pos += int64(len(entry.Raw)) + 1
+	}
+
+	return qres, page, err
+}
+
+// Read the whole log, paging backwards from the newest lines, entries are returned oldest first
+func LogViewAll(instance bool) (*status, []LogEntry, error) {
+	pages := [][]LogEntry{}
+	opts := LogViewOptions{Lines: 100, Instance: instance}
+
+	var qres *status
+	for {
+		qres1, page, err := LogView(opts)
+		if err != nil || !qres1.IsSuccess() {
+			return qres1, flattenPages(pages), err
+		}
+		qres = qres1
+
+		pages = append(pages, page.Entries)
+		if len(page.Entries) == 0 || page.LastPos <= 0 || (opts.BeginPos > 0 && page.LastPos >= opts.BeginPos) {
+			break
+		}
+
+		opts.BeginPos = page.LastPos
+	}
+
+	return qres, flattenPages(pages), nil
+}
+
+// Poll the log every interval and deliver new entries on the returned channel
+// Only entries written after LogFollow is called are delivered, close stop to end following
+func LogFollow(instance bool, interval time.Duration, stop <-chan struct{}) <-chan LogEntry {
+	entries := make(chan LogEntry, 100)
+
+	go func() {
+		defer close(entries)
+
+		// Start at the current end of the log
+		var seen int64 = -1
+		for {
+			if seen < 0 {
+				qres, page, err := LogView(LogViewOptions{Lines: 1, Instance: instance})
+				if err == nil && qres.IsSuccess() {
+					seen = page.FileSize
+				}
+			} else {
+				seen = followPage(instance, seen, entries, stop)
+			}
+
+			select {
+			case <-stop:
+				return
+			case <-time.After(interval):
+			}
+		}
+	}()
+
+	return entries
+}
+
+// Deliver every entry written after the log was seen at size seen, returns the new size of the log
+func followPage(instance bool, seen int64, entries chan<- LogEntry, stop <-chan struct{}) int64 {
+	pages := [][]LogEntry{}
+	opts := LogViewOptions{Lines: 100, Instance: instance}
+	size := int64(-1)
+
+	// Page backwards from the newest lines until we reach the lines already delivered
+	for {
+		qres, page, err := LogView(opts)
+		if err != nil || !qres.IsSuccess() {
+			return seen
+		}
+
+		if size < 0 {
+			size = page.FileSize
+			if size == seen {
+				return seen
+			}
+
+			// The log was rotated, everything in the new file is new
+			if size < seen {
+				seen = 0
+			}
+		}
+
+		fresh := []LogEntry{}
+		for _, entry := range page.Entries {
+			if entry.Pos >= seen {
+				fresh = append(fresh, entry)
+			}
+		}
+		pages = append(pages, fresh)
+
+		if len(page.Entries) == 0 || page.LastPos <= seen || (opts.BeginPos > 0 && page.LastPos >= opts.BeginPos) {
+			break
+		}
+		opts.BeginPos = page.LastPos
+	}
+
+	for _, entry := range flattenPages(pages) {
+		select {
+		case entries <- entry:
+		case <-stop:
+			return size
+		}
+	}
+
+	return size
+}
+
+// Join pages read newest first into a single list of entries, oldest first
+func flattenPages(pages [][]LogEntry) []LogEntry {
+	entries := []LogEntry{}
+	for i := len(pages) - 1; i >= 0; i-- {
+		entries = append(entries, pages[i]...)
+	}
+
+	return entries
+}
+
+// Parse a log line, lines that don't match the log format are returned with only Message and Raw set
+func ParseLogLine(line string) LogEntry {
+	entry := LogEntry{Message: line, Raw: line}
+
+	parts := strings.SplitN(line, "|", 5)
+	if len(parts) != 5 {
+		return entry
+	}
+
+	t, err := time.Parse(logTimeFormat, strings.TrimSpace(parts[0]))
+	if err != nil {
+		return entry
+	}
+
+	entry.Time = t
+	entry.Level = strings.TrimSpace(parts[1])
+	entry.Channel = strings.TrimSpace(parts[2])
+	entry.ServerId, _ = strconv.ParseInt(strings.TrimSpace(parts[3]), 10, 64)
+	entry.Message = strings.TrimSpace(parts[4])
+
+	return entry
+}
+
+func (e LogEntry) String() string {
+	if e.Time.IsZero() {
+		return e.Raw
+	}
+
+	return fmt.Sprintf("%v %v %v %v", e.Time.Format(logTimeFormat), e.Level, e.Channel, e.Message)
+}
@@ -0,0 +1,63 @@
+package ts3
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLogLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		entry LogEntry
+	}{
+		{
+			name: "virtual server entry",
+			line: "2020-06-20 12:00:00.123456|INFO    |VirtualServerBase|1  |client connected 'Sam'(id:2) from 203.0.113.7:52000",
+			entry: LogEntry{
+				Time:     time.Date(2020, 6, 20, 12, 0, 0, 123456000, time.UTC),
+				Level:    "INFO",
+				Channel:  "VirtualServerBase",
+				ServerId: 1,
+				Message:  "client connected 'Sam'(id:2) from 203.0.113.7:52000",
+			},
+		},
+		{
+			name: "instance entry",
+			line: "2020-06-20 11:59:58.000001|WARNING |Accounting    |   |Unable to open licensekey.dat, falling back to limited functionality",
+			entry: LogEntry{
+				Time:    time.Date(2020, 6, 20, 11, 59, 58, 1000, time.UTC),
+				Level:   "WARNING",
+				Channel: "Accounting",
+				Message: "Unable to open licensekey.dat, falling back to limited functionality",
+			},
+		},
+		{
+			name: "message containing the separator",
+			line: "2020-06-20 12:01:00.000000|INFO    |Query         |1  |query from 1 203.0.113.7:10011 issued: login with account 'ops'|'ops'",
+			entry: LogEntry{
+				Time:     time.Date(2020, 6, 20, 12, 1, 0, 0, time.UTC),
+				Level:    "INFO",
+				Channel:  "Query",
+				ServerId: 1,
+				Message:  "query from 1 203.0.113.7:10011 issued: login with account 'ops'|'ops'",
+			},
+		},
+		{
+			name:  "unknown format",
+			line:  "	logging to a file is disabled",
+			entry: LogEntry{Message: "	logging to a file is disabled"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := ParseLogLine(tt.line)
+
+			tt.entry.Raw = tt.line
+			if entry != tt.entry {
+				t.Errorf("ParseLogLine(%q)\ngot  %+v\nwant %+v", tt.line, entry, tt.entry)
+			}
+		})
+	}
+}
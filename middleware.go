@@ -0,0 +1,109 @@
+package ts3
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// A command about to be sent to the WebQuery
+type Request struct {
+	// The query command, e.g. "clientlist"
+	Command string
+	// The virtual server the command targets, 0 for instance commands
+	VirtualServer int
+	// The API key the command is sent with
+	ApiKey string
+	Http   *http.Request
+}
+
+// The outcome of a Request
+type Result struct {
+	// 0 when the server could not be reached, for cached results the status of the original response
+	HttpStatus int
+	// The TeamSpeak status, nil when the request failed
+	Status *status
+	Body   string
+	// Time taken to send the request, or to look it up when Cached
+	Duration time.Duration
+	Err      error
+	// The result came from the response cache and no request was sent
+	Cached bool
+}
+
+// Handler sends a Request and returns its Result
+type Handler func(req *Request) *Result
+
+// Middleware wraps a Handler, it can inspect or change the request, call next (or not)
+// and inspect or change the result
+type Middleware func(next Handler) Handler
+
+// Hook is called after every request
+type Hook func(req *Request, res *Result)
+
+var (
+	middlewareMu sync.RWMutex
+	middleware_  []Middleware
+	// The built chain, nil until the next request after Use or a Configure function changes it
+	chain_ Handler
+)
+
+// Add middleware to the chain wrapped around every request
+// Middleware added first is the outermost and sees the request first
+// Each Middleware is called once when the chain is built, not once per request, so state set up
+// outside the returned Handler (tracers, counters) is kept. The chain is only rebuilt when Use
+// or one of the Configure functions change it
+func Use(mw ...Middleware) {
+	middlewareMu.Lock()
+	defer middlewareMu.Unlock()
+
+	middleware_ = append(middleware_, mw...)
+	chain_ = nil
+}
+
+// Register a hook that is called with the outcome of every request,
+// useful for metrics such as latency histograms
+func OnRequest(hook Hook) {
+	Use(func(next Handler) Handler {
+		return func(req *Request) *Result {
+			res := next(req)
+			hook(req, res)
+			return res
+		}
+	})
+}
+
+// The handler chain for a request, built on first use after it changes
+func handler() Handler {
+	middlewareMu.RLock()
+	h := chain_
+	middlewareMu.RUnlock()
+	if h != nil {
+		return h
+	}
+
+	middlewareMu.Lock()
+	defer middlewareMu.Unlock()
+	if chain_ != nil {
+		return chain_
+	}
+
+	// Commands the API key can't run are rejected first, cached results skip the network
+	// entirely and each retry waits for the rate limiter
+	h = scopeCheck(cached(retry(rateLimit(transport))))
+	for i := len(middleware_) - 1; i >= 0; i-- {
+		h = middleware_[i](h)
+	}
+
+	chain_ = h
+	return h
+}
+
+// Rebuild the handler chain before the next request, called when a Configure function changes it
+// Callers defer it before taking their own lock so it runs after that lock is released
+func resetHandler() {
+	middlewareMu.Lock()
+	defer middlewareMu.Unlock()
+
+	chain_ = nil
+}
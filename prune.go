@@ -0,0 +1,133 @@
+package ts3
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Number of users requested from the client database per page while pruning
+const prunePageSize int64 = 200
+
+type PruneOptions struct {
+	// Users who have not connected within this duration are pruned
+	InactiveFor time.Duration
+	// Members of these server groups are never pruned
+	ProtectedGroups []int64
+	// Report the users that would be pruned without deleting them
+	DryRun bool
+	// Number of users deleted before pausing for BatchDelay (defaults to 10)
+	BatchSize int
+	// Pause between batches to stay under the query flood limits (defaults to 1 second)
+	BatchDelay time.Duration
+}
+
+type PruneResult struct {
+	// Number of users in the client database that were checked
+	Scanned int
+	// Number of inactive users skipped because they belong to a protected group
+	Protected int
+	// Inactive users that were (or in a dry run, would be) deleted
+	Candidates []User
+	// CLDBIDs of the users that were deleted
+	Deleted []int64
+	// Users that could not be deleted mapped to the reason why
+	Failed map[int64]error
+}
+
+// Delete users who have not connected to the server within opts.InactiveFor
+// Members of opts.ProtectedGroups are kept, and opts.DryRun only reports the users that would be deleted
+func UserPrune(opts PruneOptions) (*status, *PruneResult, error) {
+	// A zero duration would make every user in the database a candidate
+	if opts.InactiveFor <= 0 {
+		return nil, nil, errors.New("PruneOptions.InactiveFor must be greater than zero")
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 10
+	}
+	if opts.BatchDelay <= 0 {
+		opts.BatchDelay = time.Second
+	}
+
+	// Look up the protected users once rather than checking each user's groups
+	protected := make(map[int64]bool)
+	for _, sgid := range opts.ProtectedGroups {
+		qres, cldbids, err := serverGroupClientDbIds(sgid)
+		if err == nil && qres.Code == ErrCodeDatabaseEmptyResult {
+			continue
+		}
+		if err != nil || !qres.IsSuccess() {
+			Log(Error, "Failed to get protected servergroup %v members \n%v\n%v", sgid, qres, err)
+			return qres, nil, err
+		}
+
+		for _, cldbid := range cldbids {
+			protected[cldbid] = true
+		}
+	}
+
+	result := &PruneResult{Failed: make(map[int64]error)}
+	cutoff := time.Now().Add(-opts.InactiveFor).Unix()
+
+	// Collect every candidate before deleting so the pages don't shift underneath us
+	var qres *status
+	for start := int64(0); ; start += prunePageSize {
+		qres1, users, err := UserList(start, prunePageSize)
+		// The database is empty or the previous page ended exactly at its end
+		if err == nil && qres1.Code == ErrCodeDatabaseEmptyResult {
+			if qres == nil {
+				qres = &status{Code: 0, Message: "ok"}
+			}
+			break
+		}
+		if err != nil || !qres1.IsSuccess() {
+			Log(Error, "Failed to list the client database for pruning \n%v\n%v", qres1, err)
+			return qres1, result, err
+		}
+		qres = qres1
+
+		for _, user := range users {
+			result.Scanned++
+			if user.LastConnectedUnix() >= cutoff {
+				continue
+			}
+
+			if protected[user.Cldbid] {
+				result.Protected++
+				continue
+			}
+
+			result.Candidates = append(result.Candidates, user)
+		}
+
+		if int64(len(users)) < prunePageSize {
+			break
+		}
+	}
+
+	if opts.DryRun {
+		Log(Notice, "Prune dry run: %v of %v users would be deleted (%v protected)", len(result.Candidates), result.Scanned, result.Protected)
+		return qres, result, nil
+	}
+
+	for i, user := range result.Candidates {
+		if i > 0 && i%opts.BatchSize == 0 {
+			time.Sleep(opts.BatchDelay)
+		}
+
+		qres1, err := UserDelete(user.Cldbid)
+		if err != nil || !qres1.IsSuccess() {
+			if err == nil {
+				err = fmt.Errorf("%v (code %v)", qres1.Message, qres1.Code)
+			}
+
+			result.Failed[user.Cldbid] = err
+			continue
+		}
+
+		result.Deleted = append(result.Deleted, user.Cldbid)
+	}
+
+	Log(Notice, "Pruned %v of %v inactive users (%v failed, %v protected)", len(result.Deleted), len(result.Candidates), len(result.Failed), result.Protected)
+	return qres, result, nil
+}
@@ -0,0 +1,105 @@
+package ts3
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Every command in the ServerQuery manual, used for tab completion
+var QueryCommands = []string{
+	"apikeyadd", "apikeydel", "apikeylist", "banadd", "banclient", "bandel", "bandelall", "banlist",
+	"bindinglist", "channeladdperm", "channelclientaddperm", "channelclientdelperm", "channelclientpermlist",
+	"channelcreate", "channeldelete", "channeldelperm", "channeledit", "channelfind", "channelgroupadd",
+	"channelgroupaddperm", "channelgroupclientlist", "channelgroupcopy", "channelgroupdel",
+	"channelgroupdelperm", "channelgrouplist", "channelgrouppermlist", "channelgrouprename", "channelinfo",
+	"channellist", "channelmove", "channelpermlist", "clientaddperm", "clientdbdelete", "clientdbedit",
+	"clientdbfind", "clientdbinfo", "clientdblist", "clientdelperm", "clientedit", "clientfind",
+	"clientgetdbidfromuid", "clientgetids", "clientgetnamefromdbid", "clientgetnamefromuid",
+	"clientgetuidfromclid", "clientinfo", "clientkick", "clientlist", "clientmove", "clientpermlist",
+	"clientpoke", "clientsetserverquerylogin", "clientupdate", "complainadd", "complaindel",
+	"complaindelall", "complainlist", "custominfo", "customsearch", "ftcreatedir", "ftdeletefile",
+	"ftgetfileinfo", "ftgetfilelist", "ftinitdownload", "ftinitupload", "ftlist", "ftrenamefile", "ftstop",
+	"gm", "hostinfo", "instanceedit", "instanceinfo", "logadd", "logview", "messageadd", "messagedel",
+	"messageget", "messagelist", "messageupdateflag", "permfind", "permget", "permidgetbyname",
+	"permissionlist", "permoverview", "permreset", "privilegekeyadd", "privilegekeydelete",
+	"privilegekeylist", "privilegekeyuse", "queryloginadd", "querylogindel", "queryloginlist",
+	"sendtextmessage", "servercreate", "serverdelete", "serveredit", "servergroupadd",
+	"servergroupaddclient", "servergroupaddperm", "servergroupautoaddperm", "servergroupautodelperm",
+	"servergroupclientlist", "servergroupcopy", "servergroupdel", "servergroupdelclient",
+	"servergroupdelperm", "servergrouplist", "servergrouppermlist", "servergrouprename",
+	"servergroupsbyclientid", "serveridgetbyport", "serverinfo", "serverlist", "serverprocessstop",
+	"serverrequestconnectioninfo", "serversnapshotcreate", "serversnapshotdeploy", "serverstart",
+	"serverstop", "servertemppasswordadd", "servertemppassworddel", "servertemppasswordlist",
+	"setclientchannelgroup", "tokenadd", "tokendelete", "tokenlist", "tokenuse", "version", "whoami",
+}
+
+// Commands that are sent to the instance rather than the selected virtual server
+var instanceCommands = map[string]bool{
+	"bindinglist":       true,
+	"hostinfo":          true,
+	"instanceedit":      true,
+	"instanceinfo":      true,
+	"servercreate":      true,
+	"serverdelete":      true,
+	"serveridgetbyport": true,
+	"serverlist":        true,
+	"serverprocessstop": true,
+	"serverstart":       true,
+	"serverstop":        true,
+	"version":           true,
+}
+
+// Send any query command, use this for commands the library doesn't implement
+// Args are written the same way as the ServerQuery, "key=value" parameters and "-flag" options:
+//
+//	ts3.Query("clientdbfind", "pattern=abc123=", "-uid")
+//
+// Each item in the response body is returned as a map of its properties
+func Query(command string, args ...string) (*status, []map[string]string, error) {
+	return QueryAs(api_, command, args...)
+}
+
+// Send any query command authenticated with apiKey instead of the configured key,
+// e.g. a short lived key from ApiKeyMint. Other callers keep using the configured key
+func QueryAs(apiKey string, command string, args ...string) (*status, []map[string]string, error) {
+	queries := []KeyValue{}
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") && !strings.Contains(arg, "=") {
+			queries = append(queries, KeyValue{key: arg})
+			continue
+		}
+
+		kv := strings.SplitN(arg, "=", 2)
+		if len(kv) != 2 {
+			return nil, nil, fmt.Errorf("argument %q must be key=value or -flag", arg)
+		}
+		queries = append(queries, KeyValue{key: kv[0], value: kv[1]})
+	}
+
+	command = strings.ToLower(command)
+	sid := virtualServer_
+	if instanceCommands[command] {
+		sid = 0
+	}
+
+	qres, body, err := send(apiKey, sid, command, [][]KeyValue{queries}, false)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to execute %v \n%v\n%v", command, qres, err)
+		return qres, nil, err
+	}
+
+	var raw []map[string]interface{}
+	json.Unmarshal([]byte(body), &raw)
+
+	items := []map[string]string{}
+	for _, r := range raw {
+		item := make(map[string]string)
+		for k, v := range r {
+			item[k] = fmt.Sprint(v)
+		}
+		items = append(items, item)
+	}
+
+	return qres, items, err
+}
@@ -0,0 +1,145 @@
+package ts3
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type QueryLogin struct {
+	Cldbid int64  `json:"cldbid,string"`
+	Sid    int64  `json:"sid,string"`
+	Name   string `json:"client_login_name"`
+	// Only returned when the login is created
+	Password string `json:"client_login_password"`
+}
+
+// A rotation in progress, the old login keeps working until Confirm is called
+type QueryLoginRotation struct {
+	Old QueryLogin
+	New QueryLogin
+}
+
+// Create a ServerQuery login. A cldbid of 0 creates a new query client to own the login
+func QueryLoginAdd(name string, cldbid int64) (*status, *QueryLogin, error) {
+	queries := []KeyValue{
+		{key: "client_login_name", value: name},
+	}
+	if cldbid > 0 {
+		queries = append(queries, KeyValue{key: "cldbid", value: i64tostr(cldbid)})
+	}
+
+	qres, body, err := get("queryloginadd", false, queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to create query login %v \n%v\n%v", name, qres, err)
+		return qres, nil, err
+	}
+
+	var logins []QueryLogin
+	json.Unmarshal([]byte(body), &logins)
+	if len(logins) == 0 {
+		return qres, nil, err
+	}
+
+	return qres, &logins[0], err
+}
+
+// List ServerQuery logins, pattern filters on the login name and can use % as a wildcard
+func QueryLoginList(pattern string) (*status, []QueryLogin, error) {
+	queries := []KeyValue{}
+	if pattern != "" {
+		queries = append(queries, KeyValue{key: "pattern", value: pattern})
+	}
+
+	qres, body, err := get("queryloginlist", false, queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to list query logins \n%v\n%v", qres, err)
+		return qres, nil, err
+	}
+
+	var logins []QueryLogin
+	json.Unmarshal([]byte(body), &logins)
+	return qres, logins, err
+}
+
+// Delete the ServerQuery login belonging to a client
+func QueryLoginDel(cldbid int64) (*status, error) {
+	queries := []KeyValue{
+		{key: "cldbid", value: i64tostr(cldbid)},
+	}
+
+	qres, _, err := get("querylogindel", false, queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to delete the query login for cldbid %v \n%v\n%v", cldbid, qres, err)
+	}
+
+	return qres, err
+}
+
+// Start rotating the login belonging to oldCldbid. A new login called newName is created
+// with the same server groups and returned, the old login is only deleted when Confirm is called
+func QueryLoginRotate(oldCldbid int64, newName string) (*status, *QueryLoginRotation, error) {
+	qres, logins, err := QueryLoginList("")
+	if err != nil || !qres.IsSuccess() {
+		return qres, nil, err
+	}
+
+	rotation := &QueryLoginRotation{}
+	for _, login := range logins {
+		if login.Cldbid == oldCldbid {
+			rotation.Old = login
+		}
+	}
+	if rotation.Old.Cldbid == 0 {
+		return qres, nil, fmt.Errorf("cldbid %v does not have a query login", oldCldbid)
+	}
+
+	qres, groups, err := ServerGroupsByClientDbId(oldCldbid)
+	if err != nil || !qres.IsSuccess() {
+		return qres, nil, err
+	}
+
+	qres, login, err := QueryLoginAdd(newName, 0)
+	if err != nil || !qres.IsSuccess() {
+		return qres, nil, err
+	}
+	rotation.New = *login
+
+	// Give the new login the same permissions, new clients are already in the default group
+	for _, group := range groups {
+		qres1, err := ServerGroupsAddClient(group.Id, login.Cldbid)
+		if err == nil && (qres1.IsSuccess() || qres1.Code == ErrCodeDuplicateEntry) {
+			continue
+		}
+
+		Log(Error, "Failed to copy servergroup %v to the new query login, removing it", group.Id)
+		if err == nil {
+			err = fmt.Errorf("failed to add the new login to servergroup %v: %v", group.Id, qres1.Message)
+		}
+
+		qres2, abortErr := rotation.Abort()
+		if abortErr == nil && !qres2.IsSuccess() {
+			abortErr = fmt.Errorf("%v (code %v)", qres2.Message, qres2.Code)
+		}
+		if abortErr != nil {
+			err = fmt.Errorf("%v, removing the new login %v (cldbid %v) also failed: %v", err, login.Name, login.Cldbid, abortErr)
+		}
+		return qres1, nil, err
+	}
+
+	return qres, rotation, err
+}
+
+// The new login works, delete the old one
+func (r *QueryLoginRotation) Confirm() (*status, error) {
+	return QueryLoginDel(r.Old.Cldbid)
+}
+
+// The new login doesn't work, delete it and the query client created for it and keep the old one
+func (r *QueryLoginRotation) Abort() (*status, error) {
+	qres, err := QueryLoginDel(r.New.Cldbid)
+	if err != nil || !qres.IsSuccess() {
+		return qres, err
+	}
+
+	return UserDelete(r.New.Cldbid)
+}
@@ -0,0 +1,110 @@
+package ts3
+
+import (
+	"sync"
+	"time"
+)
+
+// A token bucket limiting how quickly commands are sent, shared by every goroutine using the package
+// The TeamSpeak defaults are 10 commands every 3 seconds (serverinstance_serverquery_flood_*)
+type RateLimit struct {
+	// Number of commands allowed per Window
+	Commands int
+	Window   time.Duration
+	// Number of commands that can be sent back to back before limiting starts (defaults to Commands)
+	Burst int
+}
+
+type bucket struct {
+	mu       sync.Mutex
+	rate     float64 // tokens per second
+	capacity float64
+	tokens   float64
+	last     time.Time
+}
+
+var (
+	limiterMu   sync.RWMutex
+	limiter     *bucket
+	whitelisted = make(map[string]bool)
+)
+
+// Limit the rate commands are sent at, pass a zero RateLimit to disable limiting
+func ConfigureRateLimit(limit RateLimit) {
+	defer resetHandler()
+	limiterMu.Lock()
+	defer limiterMu.Unlock()
+
+	if limit.Commands <= 0 || limit.Window <= 0 {
+		limiter = nil
+		return
+	}
+	if limit.Burst <= 0 {
+		limit.Burst = limit.Commands
+	}
+
+	limiter = &bucket{
+		rate:     float64(limit.Commands) / limit.Window.Seconds(),
+		capacity: float64(limit.Burst),
+		tokens:   float64(limit.Burst),
+		last:     time.Now(),
+	}
+}
+
+// Mark an API key as whitelisted so requests using it skip the rate limit
+// Use this when the server exempts your client from flood protection (query_ip_allowlist.txt)
+func WhitelistApiKey(apiKey string, whitelist bool) {
+	limiterMu.Lock()
+	defer limiterMu.Unlock()
+
+	if whitelist {
+		whitelisted[apiKey] = true
+	} else {
+		delete(whitelisted, apiKey)
+	}
+}
+
+// Wraps a handler so it waits for the rate limiter before sending each request
+func rateLimit(next Handler) Handler {
+	limiterMu.RLock()
+	b := limiter
+	limiterMu.RUnlock()
+
+	if b == nil {
+		return next
+	}
+
+	return func(req *Request) *Result {
+		limiterMu.RLock()
+		skip := whitelisted[req.ApiKey]
+		limiterMu.RUnlock()
+
+		if !skip {
+			b.wait()
+		}
+		return next(req)
+	}
+}
+
+// Block until a token is available and take it
+func (b *bucket) wait() {
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.capacity {
+			b.tokens = b.capacity
+		}
+		b.last = now
+
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return
+		}
+
+		delay := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.mu.Unlock()
+		time.Sleep(delay)
+	}
+}
@@ -0,0 +1,142 @@
+package ts3
+
+import (
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TeamSpeak error codes returned when the query is sending commands too quickly
+const (
+	ErrCodeClientFlooding = 524
+	ErrCodeFloodBan       = 3331
+)
+
+// Controls how failed requests are retried
+// Network errors, HTTP 5xx responses and flood errors are retried with exponential backoff and jitter
+type RetryPolicy struct {
+	// Total number of attempts including the first, 1 or less disables retrying
+	MaxAttempts int
+	// Delay before the first retry, doubled for each attempt after that (defaults to 250ms)
+	BaseDelay time.Duration
+	// Upper limit on the delay between attempts (defaults to 10 seconds)
+	MaxDelay time.Duration
+	// Retry commands that change the server, these could be applied twice if the first response was lost
+	RetryNonIdempotent bool
+	// Commands that change the server but are safe to retry, e.g. "servergroupaddclient"
+	RetryCommands []string
+}
+
+var (
+	retryMu     sync.RWMutex
+	retryPolicy *RetryPolicy
+)
+
+// Set the retry policy used by every request, pass a zero RetryPolicy to disable retrying
+func ConfigureRetry(policy RetryPolicy) {
+	defer resetHandler()
+	retryMu.Lock()
+	defer retryMu.Unlock()
+
+	if policy.MaxAttempts <= 1 {
+		retryPolicy = nil
+		return
+	}
+	if policy.BaseDelay <= 0 {
+		policy.BaseDelay = 250 * time.Millisecond
+	}
+	if policy.MaxDelay <= 0 {
+		policy.MaxDelay = 10 * time.Second
+	}
+
+	retryPolicy = &policy
+}
+
+// Wraps a handler with the configured retry policy
+func retry(next Handler) Handler {
+	retryMu.RLock()
+	policy := retryPolicy
+	retryMu.RUnlock()
+
+	if policy == nil {
+		return next
+	}
+
+	return func(req *Request) *Result {
+		if !policy.allows(req.Command) {
+			return next(req)
+		}
+
+		var res *Result
+		for attempt := 1; ; attempt++ {
+			res = next(req)
+			if attempt >= policy.MaxAttempts || !retryable(res) {
+				return res
+			}
+
+			delay := policy.delay(attempt)
+			Log(Notice, "Retrying %v in %v (attempt %v of %v)", req.Command, delay, attempt+1, policy.MaxAttempts)
+			time.Sleep(delay)
+
+			// The body of a POST request has been read and needs to be rewound
+			if req.Http.GetBody != nil {
+				body, err := req.Http.GetBody()
+				if err != nil {
+					return res
+				}
+				req.Http.Body = body
+			}
+		}
+	}
+}
+
+// Whether the policy permits retrying a command
+func (p *RetryPolicy) allows(command string) bool {
+	if p.RetryNonIdempotent || isReadCommand(command) {
+		return true
+	}
+
+	for _, c := range p.RetryCommands {
+		if strings.EqualFold(c, command) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Exponential backoff with jitter, the delay is between half and all of the backoff
+func (p *RetryPolicy) delay(attempt int) time.Duration {
+	backoff := p.BaseDelay << uint(attempt-1)
+	if backoff > p.MaxDelay || backoff <= 0 {
+		backoff = p.MaxDelay
+	}
+
+	half := int64(backoff / 2)
+	return time.Duration(half + rand.Int63n(half+1))
+}
+
+// Whether a result is worth trying again
+func retryable(res *Result) bool {
+	if res.Err != nil || res.HttpStatus >= http.StatusInternalServerError {
+		return true
+	}
+
+	return res.Status != nil && (res.Status.Code == ErrCodeClientFlooding || res.Status.Code == ErrCodeFloodBan)
+}
+
+// Whether a command only reads from the server and can safely be sent more than once
+func isReadCommand(command string) bool {
+	command = strings.ToLower(command)
+	switch command {
+	case "whoami", "version", "hostinfo", "instanceinfo", "serverinfo", "serveridgetbyport",
+		"clientdbinfo", "clientdbfind", "customsearch", "customsearchinfo", "clientinfo", "clientfind",
+		"channelinfo", "channelfind", "logview", "ftgetfileinfo", "serverrequestconnectioninfo",
+		"servergroupsbyclientid", "permget", "permfind", "permoverview", "permidgetbyname":
+		return true
+	}
+
+	return strings.HasSuffix(command, "list") || strings.HasPrefix(command, "clientget")
+}
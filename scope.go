@@ -0,0 +1,129 @@
+package ts3
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// ErrInsufficientScope is matched by errors.Is for any *ScopeError
+var ErrInsufficientScope = errors.New("api key scope does not allow this command")
+
+// Returned without contacting the server when a command needs a higher scope than the API key has
+type ScopeError struct {
+	Command  string
+	Required ApiKeyScope
+	Scope    ApiKeyScope
+}
+
+func (e *ScopeError) Error() string {
+	return fmt.Sprintf("%v requires an api key with the %v scope, the current key has the %v scope", e.Command, e.Required, e.Scope)
+}
+
+func (e *ScopeError) Is(target error) bool {
+	return target == ErrInsufficientScope
+}
+
+// Commands that can only be sent with a manage key, including credential lists such as apikeylist
+var manageCommands = map[string]bool{
+	"apikeyadd":            true,
+	"apikeydel":            true,
+	"apikeylist":           true,
+	"instanceedit":         true,
+	"queryloginadd":        true,
+	"querylogindel":        true,
+	"queryloginlist":       true,
+	"servercreate":         true,
+	"serverdelete":         true,
+	"serverprocessstop":    true,
+	"serversnapshotdeploy": true,
+	"serverstart":          true,
+	"serverstop":           true,
+}
+
+// Commands that change the server and need at least a write key
+var writeCommands = map[string]bool{
+	"banadd": true, "banclient": true, "bandel": true, "bandelall": true,
+	"channeladdperm": true, "channelclientaddperm": true, "channelclientdelperm": true, "channelcreate": true,
+	"channeldelete": true, "channeldelperm": true, "channeledit": true, "channelmove": true,
+	"channelgroupadd": true, "channelgroupaddperm": true, "channelgroupcopy": true, "channelgroupdel": true,
+	"channelgroupdelperm": true, "channelgrouprename": true, "setclientchannelgroup": true,
+	"clientaddperm": true, "clientdbdelete": true, "clientdbedit": true, "clientdelperm": true,
+	"clientedit": true, "clientkick": true, "clientmove": true, "clientpoke": true,
+	"clientsetserverquerylogin": true, "clientupdate": true,
+	"complainadd": true, "complaindel": true, "complaindelall": true,
+	"ftcreatedir": true, "ftdeletefile": true, "ftinitupload": true, "ftrenamefile": true,
+	"gm": true, "sendtextmessage": true, "logadd": true,
+	"messageadd": true, "messagedel": true, "messageupdateflag": true, "permreset": true,
+	"privilegekeyadd": true, "privilegekeydelete": true, "privilegekeyuse": true,
+	"tokenadd": true, "tokendelete": true, "tokenuse": true,
+	"serveredit": true, "servertemppasswordadd": true, "servertemppassworddel": true,
+	"servergroupadd": true, "servergroupaddclient": true, "servergroupaddperm": true,
+	"servergroupautoaddperm": true, "servergroupautodelperm": true, "servergroupcopy": true,
+	"servergroupdel": true, "servergroupdelclient": true, "servergroupdelperm": true, "servergrouprename": true,
+}
+
+var (
+	scopeMu  sync.RWMutex
+	keyScope ApiKeyScope
+)
+
+// Tell the library the scope of the configured API key so commands it can't run are rejected locally
+// The scope can't be detected reliably since apikeylist doesn't say which key is in use
+// An empty scope turns the check off
+func ConfigureApiKeyScope(scope ApiKeyScope) {
+	defer resetHandler()
+	scopeMu.Lock()
+	defer scopeMu.Unlock()
+
+	keyScope = scope
+}
+
+// The scope a command needs
+func requiredScope(command string) ApiKeyScope {
+	command = strings.ToLower(command)
+	switch {
+	case manageCommands[command]:
+		return ScopeManage
+	case writeCommands[command]:
+		return ScopeWrite
+	default:
+		// Reads and commands we don't know about are left for the server to decide
+		return ScopeRead
+	}
+}
+
+// Whether a key with scope can run commands that need required
+func scopeAllows(scope ApiKeyScope, required ApiKeyScope) bool {
+	rank := map[ApiKeyScope]int{ScopeRead: 1, ScopeWrite: 2, ScopeManage: 3}
+	return rank[scope] >= rank[required]
+}
+
+// Wraps a handler so commands the API key can't run fail before they are sent
+func scopeCheck(next Handler) Handler {
+	scopeMu.RLock()
+	scope := keyScope
+	scopeMu.RUnlock()
+
+	if scope == "" {
+		return next
+	}
+
+	return func(req *Request) *Result {
+		// The configured scope belongs to the configured key, the server checks keys passed to QueryAs
+		if req.ApiKey != api_ {
+			return next(req)
+		}
+
+		required := requiredScope(req.Command)
+		if !scopeAllows(scope, required) {
+			err := &ScopeError{Command: req.Command, Required: required, Scope: scope}
+			Log(Error, "%v", err)
+			return &Result{HttpStatus: http.StatusForbidden, Err: err}
+		}
+
+		return next(req)
+	}
+}
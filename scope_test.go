@@ -0,0 +1,41 @@
+package ts3
+
+import "testing"
+
+func TestRequiredScope(t *testing.T) {
+	tests := map[string]ApiKeyScope{
+		"serverlist":     ScopeRead,
+		"custominfo":     ScopeRead,
+		"messageget":     ScopeRead,
+		"ftinitdownload": ScopeRead,
+		"notacommand":    ScopeRead,
+		"servergroupadd": ScopeWrite,
+		"TokenAdd":       ScopeWrite,
+		"ftinitupload":   ScopeWrite,
+		"apikeylist":     ScopeManage,
+		"queryloginlist": ScopeManage,
+		"serverstop":     ScopeManage,
+	}
+
+	for command, want := range tests {
+		if got := requiredScope(command); got != want {
+			t.Errorf("requiredScope(%q) = %v, want %v", command, got, want)
+		}
+	}
+}
+
+// Guards against typos in the scope tables, which would silently skip the local check
+func TestScopeCommandsAreKnown(t *testing.T) {
+	known := make(map[string]bool)
+	for _, command := range QueryCommands {
+		known[command] = true
+	}
+
+	for _, commands := range []map[string]bool{manageCommands, writeCommands} {
+		for command := range commands {
+			if !known[command] {
+				t.Errorf("%q is not in QueryCommands", command)
+			}
+		}
+	}
+}
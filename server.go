@@ -15,6 +15,126 @@ type VirtualServer struct {
 	Autostart     bool   `json:"virtualserver_autostart"`
 }
 
+// Detailed information about a virtual server returned by serverinfo
+type VirtualServerInfo struct {
+	Id                 int64  `json:"virtualserver_id,string"`
+	UniqueIdentifier   string `json:"virtualserver_unique_identifier"`
+	Port               int64  `json:"virtualserver_port,string"`
+	Status             string `json:"virtualserver_status"`
+	Name               string `json:"virtualserver_name"`
+	WelcomeMessage     string `json:"virtualserver_welcomemessage"`
+	Platform           string `json:"virtualserver_platform"`
+	Version            string `json:"virtualserver_version"`
+	Created            int64  `json:"virtualserver_created,string"`
+	Uptime             int64  `json:"virtualserver_uptime,string"`
+	Autostart          Flag   `json:"virtualserver_autostart"`
+	HasPassword        Flag   `json:"virtualserver_flag_password"`
+	MaxClients         int64  `json:"virtualserver_maxclients,string"`
+	ReservedSlots      int64  `json:"virtualserver_reserved_slots,string"`
+	ClientsOnline      int64  `json:"virtualserver_clientsonline,string"`
+	QueryClientsOnline int64  `json:"virtualserver_queryclientsonline,string"`
+	ChannelsOnline     int64  `json:"virtualserver_channelsonline,string"`
+	ClientConnections  int64  `json:"virtualserver_client_connections,string"`
+	QueryConnections   int64  `json:"virtualserver_query_client_connections,string"`
+	IconId             int64  `json:"virtualserver_icon_id,string"`
+
+	// Host message and banner
+	HostMessage           string `json:"virtualserver_hostmessage"`
+	HostMessageMode       int64  `json:"virtualserver_hostmessage_mode,string"`
+	HostBannerUrl         string `json:"virtualserver_hostbanner_url"`
+	HostBannerGfxUrl      string `json:"virtualserver_hostbanner_gfx_url"`
+	HostBannerGfxInterval int64  `json:"virtualserver_hostbanner_gfx_interval,string"`
+	HostBannerMode        int64  `json:"virtualserver_hostbanner_mode,string"`
+
+	// Groups assigned to new clients
+	DefaultServerGroup       int64 `json:"virtualserver_default_server_group,string"`
+	DefaultChannelGroup      int64 `json:"virtualserver_default_channel_group,string"`
+	DefaultChannelAdminGroup int64 `json:"virtualserver_default_channel_admin_group,string"`
+
+	// Connection quality
+	TotalPacketLoss float64 `json:"virtualserver_total_packetloss_total,string"`
+	TotalPing       float64 `json:"virtualserver_total_ping,string"`
+
+	// Bandwidth and traffic counters
+	BytesSentTotal                 int64 `json:"connection_bytes_sent_total,string"`
+	BytesReceivedTotal             int64 `json:"connection_bytes_received_total,string"`
+	PacketsSentTotal               int64 `json:"connection_packets_sent_total,string"`
+	PacketsReceivedTotal           int64 `json:"connection_packets_received_total,string"`
+	BandwidthSentLastSecond        int64 `json:"connection_bandwidth_sent_last_second_total,string"`
+	BandwidthReceivedLastSecond    int64 `json:"connection_bandwidth_received_last_second_total,string"`
+	BandwidthSentLastMinute        int64 `json:"connection_bandwidth_sent_last_minute_total,string"`
+	BandwidthReceivedLastMinute    int64 `json:"connection_bandwidth_received_last_minute_total,string"`
+	MonthBytesDownloaded           int64 `json:"virtualserver_month_bytes_downloaded,string"`
+	MonthBytesUploaded             int64 `json:"virtualserver_month_bytes_uploaded,string"`
+	TotalBytesDownloaded           int64 `json:"virtualserver_total_bytes_downloaded,string"`
+	TotalBytesUploaded             int64 `json:"virtualserver_total_bytes_uploaded,string"`
+	FileTransferBandwidthSent      int64 `json:"connection_filetransfer_bandwidth_sent,string"`
+	FileTransferBandwidthReceived  int64 `json:"connection_filetransfer_bandwidth_received,string"`
+	FileTransferBytesSentTotal     int64 `json:"connection_filetransfer_bytes_sent_total,string"`
+	FileTransferBytesReceivedTotal int64 `json:"connection_filetransfer_bytes_received_total,string"`
+}
+
+// Properties that can be set when creating or editing a virtual server
+// Only the fields that are not nil are sent to the server, use ts3.String(), ts3.Int64() and ts3.Bool() to set them
+type ServerProperties struct {
+	Name                     *string
+	Port                     *int64
+	WelcomeMessage           *string
+	MaxClients               *int64
+	Password                 *string
+	HostMessage              *string
+	HostBannerUrl            *string
+	HostBannerGfxUrl         *string
+	HostBannerGfxInterval    *int64
+	HostBannerMode           *int64
+	DefaultServerGroup       *int64
+	DefaultChannelGroup      *int64
+	DefaultChannelAdminGroup *int64
+	Autostart                *bool
+}
+
+// Convert the properties that have been set into query parameters
+func (p ServerProperties) queries() []KeyValue {
+	queries := []KeyValue{}
+
+	str := func(key string, v *string) {
+		if v != nil {
+			queries = append(queries, KeyValue{key: key, value: *v})
+		}
+	}
+	num := func(key string, v *int64) {
+		if v != nil {
+			queries = append(queries, KeyValue{key: key, value: i64tostr(*v)})
+		}
+	}
+
+	str("virtualserver_name", p.Name)
+	num("virtualserver_port", p.Port)
+	str("virtualserver_welcomemessage", p.WelcomeMessage)
+	num("virtualserver_maxclients", p.MaxClients)
+	str("virtualserver_password", p.Password)
+	str("virtualserver_hostmessage", p.HostMessage)
+	str("virtualserver_hostbanner_url", p.HostBannerUrl)
+	str("virtualserver_hostbanner_gfx_url", p.HostBannerGfxUrl)
+	num("virtualserver_hostbanner_gfx_interval", p.HostBannerGfxInterval)
+	num("virtualserver_hostbanner_mode", p.HostBannerMode)
+	num("virtualserver_default_server_group", p.DefaultServerGroup)
+	num("virtualserver_default_channel_group", p.DefaultChannelGroup)
+	num("virtualserver_default_channel_admin_group", p.DefaultChannelAdminGroup)
+	if p.Autostart != nil {
+		queries = append(queries, KeyValue{key: "virtualserver_autostart", value: booltostr(*p.Autostart)})
+	}
+
+	return queries
+}
+
+// The result of creating a virtual server
+type CreatedServer struct {
+	Id    int64  `json:"sid,string"`
+	Port  int64  `json:"virtualserver_port,string"`
+	Token string `json:"token"` // Privilege key for the server admin group
+}
+
 // Send a global message to the current server
 func ServerGlobalMessage(msg string) (*status, error) {
 	queries := []KeyValue{
@@ -70,3 +190,105 @@ func ServersList() (*status, []VirtualServer, error) {
 
 	return qres, servers, err
 }
+
+// Create a new virtual server, the server is started once it has been created
+func ServerCreate(name string, props ServerProperties) (*status, *CreatedServer, error) {
+	props.Name = &name
+
+	qres, body, err := get("servercreate", true, props.queries())
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to create server %v \n%v\n%v", name, qres, err)
+		return qres, nil, err
+	}
+
+	var servers []CreatedServer
+	json.Unmarshal([]byte(body), &servers)
+	if len(servers) == 0 {
+		return qres, nil, err
+	}
+
+	return qres, &servers[0], err
+}
+
+// Delete a virtual server, the server must be stopped first
+func ServerDelete(sid int64) (*status, error) {
+	queries := []KeyValue{
+		{key: "sid", value: i64tostr(sid)},
+	}
+
+	qres, _, err := get("serverdelete", true, queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to delete server %v \n%v\n%v", sid, qres, err)
+	}
+
+	return qres, err
+}
+
+// Change the properties of a virtual server
+func ServerEdit(sid int64, props ServerProperties) (*status, error) {
+	qres, _, err := getSid(sid, "serveredit", props.queries())
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to edit server %v \n%v\n%v", sid, qres, err)
+	}
+
+	return qres, err
+}
+
+// Get detailed information about a virtual server
+func ServerInfo(sid int64) (*status, *VirtualServerInfo, error) {
+	qres, body, err := getSid(sid, "serverinfo")
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to get information for server %v \n%v\n%v", sid, qres, err)
+		return qres, nil, err
+	}
+
+	var info []VirtualServerInfo
+	json.Unmarshal([]byte(body), &info)
+	if len(info) == 0 {
+		return qres, nil, err
+	}
+
+	return qres, &info[0], err
+}
+
+// Find the ID of the virtual server running on a UDP port
+func ServerIdGetByPort(port int64) (*status, int64, error) {
+	queries := []KeyValue{
+		{key: "virtualserver_port", value: i64tostr(port)},
+	}
+
+	qres, body, err := get("serveridgetbyport", true, queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to get the server on port %v \n%v\n%v", port, qres, err)
+		return qres, -1, err
+	}
+
+	type sid_ struct {
+		Id int64 `json:"server_id,string"`
+	}
+
+	var sid []sid_
+	json.Unmarshal([]byte(body), &sid)
+	if len(sid) == 0 {
+		return qres, -1, err
+	}
+
+	return qres, sid[0].Id, err
+}
+
+// List the clients connected to a virtual server including their server groups
+func ServerSessions(sid int64) (*status, []Session, error) {
+	queries := []KeyValue{
+		{key: "-groups", value: ""},
+	}
+
+	qres, body, err := getSid(sid, "clientlist", queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to get the clients connected to server %v \n%v\n%v", sid, qres, err)
+		return qres, nil, err
+	}
+
+	var sessions []Session
+	json.Unmarshal([]byte(body), &sessions)
+	return qres, sessions, err
+}
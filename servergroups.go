@@ -2,7 +2,6 @@ package ts3
 
 import (
 	"encoding/json"
-	"fmt"
 )
 
 type GroupType int
@@ -33,6 +32,20 @@ func ServerGroups() (*status, []ServerGroup, error) {
 	return qres, groups, err
 }
 
+// List the server groups on a virtual server other than the selected one
+func ServerGroupsBySid(sid int64) (*status, []ServerGroup, error) {
+	qres, body, err := getSid(sid, "servergrouplist")
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to get a list of server groups on server %v \n%v\n%v", sid, qres, err)
+		return qres, nil, err
+	}
+
+	var groups []ServerGroup
+	json.Unmarshal([]byte(body), &groups)
+
+	return qres, groups, err
+}
+
 // Add a client to a server group
 func ServerGroupsAddClient(sgid int64, cldbid int64) (*status, error) {
 	queries := []KeyValue{
@@ -65,8 +78,15 @@ func ServerGroupsRevokeClient(sgid int64, cldbid int64) (*status, error) {
 
 //List the users who belong to a specific server group
 func ServerGroupMembers(sgid int64) (*status, []User, error) {
+	return ServerGroupMembersWithOptions(sgid, MemberOptions{})
+}
+
+// List the users who belong to a specific server group
+// The group is resolved in a constant number of requests regardless of its size
+func ServerGroupMembersWithOptions(sgid int64, opts MemberOptions) (*status, []User, error) {
 	queries := []KeyValue{
 		{key: "sgid", value: i64tostr(sgid)},
+		{key: "-names", value: ""},
 	}
 
 	qres, body, err := get("servergroupclientlist", false, queries)
@@ -75,65 +95,71 @@ func ServerGroupMembers(sgid int64) (*status, []User, error) {
 		return qres, nil, err
 	}
 
-	// For reasons that continue to baffle me
-	// teamspeak devs use the term 'cldbid' in the servergroupclientlist
-	// yet in other endpoints they use 'client_database_id'
-	// so I need to have this struct for this one edge case....
+	// -names includes client_nickname and client_unique_identifier alongside the 'cldbid'
+	var members []User
+	json.Unmarshal([]byte(body), &members)
+
 	type cldbid_ struct {
 		Clid int64 `json:"cldbid,string"`
 	}
 
-	var cldbid []cldbid_
-	json.Unmarshal([]byte(body), &cldbid)
+	var ids []cldbid_
+	json.Unmarshal([]byte(body), &ids)
+	for i := range members {
+		members[i].Cldbid = ids[i].Clid
+	}
 
-	qres1, sessions, err := ActiveClients()
-	if err != nil {
+	qres1, members, err := resolveMembers(members, opts)
+	if err != nil || (qres1 != nil && !qres1.IsSuccess()) {
+		Log(Error, "Failed to look up servergroup %v members \n%v\n%v", sgid, qres1, err)
 		return qres1, nil, err
 	}
 
-	// Build an array of Users with their active session IDs (CLIDs) included
-	groupmembers := []User{}
-	for _, member := range cldbid {
-		_, u, err := UserFindByDbId(member.Clid)
-		if err != nil {
-			Log(Error, "Failed to look up cldbid %v \n%v", member.Clid, err)
-			continue
-		}
+	return qres, members, err
+}
 
-		u.ActiveSessionIds = sessions[member.Clid]
+// List the CLDBIDs of the users who belong to a specific server group
+func serverGroupClientDbIds(sgid int64) (*status, []int64, error) {
+	queries := []KeyValue{
+		{key: "sgid", value: i64tostr(sgid)},
+	}
 
-		groupmembers = append(groupmembers, *u)
+	qres, body, err := get("servergroupclientlist", false, queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to get servergroup %v members \n%v\n%v", sgid, qres, err)
+		return qres, nil, err
 	}
 
-	return qres, groupmembers, err
+	// For reasons that continue to baffle me
+	// teamspeak devs use the term 'cldbid' in the servergroupclientlist
+	// yet in other endpoints they use 'client_database_id'
+	// so I need to have this struct for this one edge case....
+	type cldbid_ struct {
+		Clid int64 `json:"cldbid,string"`
+	}
+
+	var members []cldbid_
+	json.Unmarshal([]byte(body), &members)
+
+	cldbids := []int64{}
+	for _, member := range members {
+		cldbids = append(cldbids, member.Clid)
+	}
+
+	return qres, cldbids, err
 }
 
-// // Pokes all active clients belonging to databaseusers in a specific server group
-func ServerGroupPoke(sgid int64, msg string) (*status, error) {
+// Pokes all active clients belonging to databaseusers in a specific server group
+func ServerGroupPoke(sgid int64, msg string) (*status, *BulkResult, error) {
 	qres, users, err := ServerGroupMembers(sgid)
 	if err != nil || !qres.IsSuccess() {
 		Log(Error, "Failed to get server group members")
-		return qres, err
-	}
-
-	attempted := 0
-	failed := 0
-
-	for _, user := range users {
-		for i := 0; i < len(user.ActiveSessionIds); i++ {
-			qres1, err := UserPoke(user.ActiveSessionIds[i], msg)
-			if err != nil || !qres1.IsSuccess() {
-				failed++
-				Log(Error, "Failed to poke %v \n%v\n%v", user.Nickname, qres1, err)
-			}
-
-			attempted++
-		}
+		return qres, nil, err
 	}
 
-	qres.Code = -1
-	qres.Message = fmt.Sprintf("%v%% of clients successfully poked (%v failed)", ((attempted-failed)/attempted)*100, failed)
-	return qres, err
+	result := Bulk(sessionIds(users), defaultBulkConcurrency, BulkPoke(msg))
+	Log(Notice, "Poked %v clients in servergroup %v (%v failed)", result.Succeeded, sgid, result.Failed)
+	return qres, result, err
 }
 
 // List a users server groups
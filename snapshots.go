@@ -0,0 +1,239 @@
+package ts3
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A virtual server snapshot as returned by serversnapshotcreate
+// The contents are opaque and should only be passed back to ServerSnapshotDeploy
+type Snapshot []byte
+
+type SnapshotDeployOptions struct {
+	// Password the snapshot was created with
+	Password string
+	// Keep the files on the server instead of removing them (-keepfiles)
+	KeepFiles bool
+	// Return a map of old channel IDs to new channel IDs (-mapping)
+	Mapping bool
+}
+
+// Maps a channel in the snapshot to the channel that was created when it was deployed
+type ChannelMapping struct {
+	OldId int64 `json:"ocid,string"`
+	NewId int64 `json:"ncid,string"`
+}
+
+// Describes a snapshot held in a SnapshotStore
+type SnapshotInfo struct {
+	Name    string
+	Sid     int64
+	Created time.Time
+}
+
+// SnapshotStore is implemented by anything that can hold snapshots, such as a directory or a bucket
+type SnapshotStore interface {
+	Save(info SnapshotInfo, snapshot Snapshot) error
+	Load(name string) (Snapshot, error)
+	List() ([]SnapshotInfo, error)
+	Delete(name string) error
+}
+
+// Controls how many snapshots of a virtual server are kept, a zero value disables that rule
+type SnapshotRetention struct {
+	MaxCount int
+	MaxAge   time.Duration
+}
+
+// Create a snapshot of a virtual server, password is optional and encrypts the snapshot
+func ServerSnapshotCreate(sid int64, password string) (*status, Snapshot, error) {
+	queries := []KeyValue{}
+	if password != "" {
+		queries = append(queries, KeyValue{key: "password", value: password})
+	}
+
+	qres, body, err := getSid(sid, "serversnapshotcreate", queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to create a snapshot of server %v \n%v\n%v", sid, qres, err)
+		return qres, nil, err
+	}
+
+	// Keep the version, salt and data fields together so they can be sent back as is
+	var parts []json.RawMessage
+	json.Unmarshal([]byte(body), &parts)
+	if len(parts) == 0 {
+		return qres, nil, errors.New("serversnapshotcreate returned an empty snapshot")
+	}
+
+	return qres, Snapshot(parts[0]), err
+}
+
+// Deploy a snapshot to a virtual server, replacing its configuration
+func ServerSnapshotDeploy(sid int64, snapshot Snapshot, opts SnapshotDeployOptions) (*status, []ChannelMapping, error) {
+	var fields map[string]string
+	if err := json.Unmarshal(snapshot, &fields); err != nil {
+		Log(Error, "Failed to read snapshot \n%v", err)
+		return nil, nil, err
+	}
+
+	queries := []KeyValue{}
+	for k, v := range fields {
+		queries = append(queries, KeyValue{key: k, value: v})
+	}
+	if opts.Password != "" {
+		queries = append(queries, KeyValue{key: "password", value: opts.Password})
+	}
+	if opts.KeepFiles {
+		queries = append(queries, KeyValue{key: "-keepfiles", value: ""})
+	}
+	if opts.Mapping {
+		queries = append(queries, KeyValue{key: "-mapping", value: ""})
+	}
+
+	// Snapshots are far too large for a URL
+	qres, body, err := postSid(sid, "serversnapshotdeploy", queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to deploy snapshot to server %v \n%v\n%v", sid, qres, err)
+		return qres, nil, err
+	}
+
+	var mapping []ChannelMapping
+	if opts.Mapping {
+		json.Unmarshal([]byte(body), &mapping)
+	}
+
+	return qres, mapping, err
+}
+
+// Create a snapshot of a virtual server, save it to store and then remove
+// old snapshots of the same server according to the retention rules
+func ServerSnapshotBackup(sid int64, password string, store SnapshotStore, retention SnapshotRetention) (*status, *SnapshotInfo, error) {
+	qres, snapshot, err := ServerSnapshotCreate(sid, password)
+	if err != nil || !qres.IsSuccess() {
+		return qres, nil, err
+	}
+
+	now := time.Now().UTC()
+	info := SnapshotInfo{
+		Name:    fmt.Sprintf("%v-%v", sid, now.Format(snapshotTimeFormat)),
+		Sid:     sid,
+		Created: now,
+	}
+
+	if err := store.Save(info, snapshot); err != nil {
+		Log(Error, "Failed to save snapshot %v \n%v", info.Name, err)
+		return qres, nil, err
+	}
+
+	snapshots, err := store.List()
+	if err != nil {
+		Log(Error, "Failed to list stored snapshots \n%v", err)
+		return qres, &info, err
+	}
+
+	// Newest first, so everything past MaxCount is the oldest
+	var existing []SnapshotInfo
+	for _, s := range snapshots {
+		if s.Sid == sid && s.Name != info.Name {
+			existing = append(existing, s)
+		}
+	}
+	sort.Slice(existing, func(i, j int) bool {
+		return existing[i].Created.After(existing[j].Created)
+	})
+
+	for i, s := range existing {
+		expired := retention.MaxAge > 0 && now.Sub(s.Created) > retention.MaxAge
+		excess := retention.MaxCount > 0 && i+1 >= retention.MaxCount
+		if !expired && !excess {
+			continue
+		}
+
+		if err := store.Delete(s.Name); err != nil {
+			Log(Error, "Failed to delete snapshot %v \n%v", s.Name, err)
+		}
+	}
+
+	return qres, &info, nil
+}
+
+// Snapshot names are "<sid>-<created>"
+const snapshotTimeFormat = "20060102T150405.000000000Z"
+
+// Stores snapshots as files in a directory
+type FileSnapshotStore struct {
+	Dir string
+}
+
+// Create a snapshot store in dir, creating the directory if it doesn't exist
+func NewFileSnapshotStore(dir string) (*FileSnapshotStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	return &FileSnapshotStore{Dir: dir}, nil
+}
+
+func (f *FileSnapshotStore) path(name string) string {
+	return filepath.Join(f.Dir, name+".snapshot")
+}
+
+// Write the snapshot to a temporary file first so a failed write never leaves a partial snapshot
+func (f *FileSnapshotStore) Save(info SnapshotInfo, snapshot Snapshot) error {
+	tmp := f.path(info.Name) + ".tmp"
+	if err := ioutil.WriteFile(tmp, snapshot, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, f.path(info.Name))
+}
+
+func (f *FileSnapshotStore) Load(name string) (Snapshot, error) {
+	return ioutil.ReadFile(f.path(name))
+}
+
+func (f *FileSnapshotStore) List() ([]SnapshotInfo, error) {
+	files, err := ioutil.ReadDir(f.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := []SnapshotInfo{}
+	for _, file := range files {
+		name := strings.TrimSuffix(file.Name(), ".snapshot")
+		if file.IsDir() || name == file.Name() {
+			continue
+		}
+
+		parts := strings.SplitN(name, "-", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		sid, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		created, err := time.Parse(snapshotTimeFormat, parts[1])
+		if err != nil {
+			continue
+		}
+
+		snapshots = append(snapshots, SnapshotInfo{Name: name, Sid: sid, Created: created})
+	}
+
+	return snapshots, nil
+}
+
+func (f *FileSnapshotStore) Delete(name string) error {
+	return os.Remove(f.path(name))
+}
@@ -1,11 +1,15 @@
 package ts3
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
 )
 
 var (
@@ -29,6 +33,14 @@ func (s status) IsSuccess() bool {
 	return s.Message == "ok" || s.Code == -1
 }
 
+// TeamSpeak error codes the library treats as an expected outcome rather than a failure
+const (
+	// Returned by list commands instead of an empty list
+	ErrCodeDatabaseEmptyResult = 1281
+	// Returned when an entry already exists, e.g. adding a client to a server group they already belong to
+	ErrCodeDuplicateEntry = 2561
+)
+
 type KeyValue struct {
 	key   string
 	value string
@@ -53,55 +65,130 @@ func SelectVirtualServer(sid int) {
 
 // HTTP Get request, taxes in optional []KeyValues which will be built as URL queries
 func get(path string, globalCmd bool, queries ...[]KeyValue) (qres *status, body string, err error) {
-	sid := ""
+	sid := 0
 	if !globalCmd {
-		sid = fmt.Sprintf("%v/", virtualServer_)
+		sid = virtualServer_
 	}
 
-	baseUrl, err := url.Parse(fmt.Sprintf("%v://%v/%v%v", scheme_, baseUrl_, sid, path))
+	return request(sid, path, queries...)
+}
+
+// HTTP Get request against a specific virtual server rather than the selected one
+func getSid(sid int64, path string, queries ...[]KeyValue) (qres *status, body string, err error) {
+	return request(int(sid), path, queries...)
+}
+
+// HTTP Post request against a specific virtual server, the []KeyValues are sent as a JSON body
+// Used for commands with parameters too large to fit in a URL such as snapshots
+func postSid(sid int64, path string, queries ...[]KeyValue) (qres *status, body string, err error) {
+	return send(api_, int(sid), path, queries, true)
+}
+
+// Build and execute a request, a sid of 0 sends the command to the instance
+func request(sid int, path string, queries ...[]KeyValue) (qres *status, body string, err error) {
+	return send(api_, sid, path, queries, false)
+}
+
+// Build and execute a GET or POST request authenticated with apiKey
+func send(apiKey string, sid int, path string, queries [][]KeyValue, post bool) (qres *status, body string, err error) {
+	prefix := ""
+	if sid > 0 {
+		prefix = fmt.Sprintf("%v/", sid)
+	}
+
+	baseUrl, err := url.Parse(fmt.Sprintf("%v://%v/%v%v", scheme_, baseUrl_, prefix, path))
 	if err != nil {
 		Log(Error, "Failed to build request URL \n%v", err)
 		return nil, "", err
 	}
 
-	// Build the query parts
+	// Build the query parts, keys starting with "-" and no value are sent as flags (e.g. -uid)
 	params := url.Values{}
+	flags := []string{}
 	if len(queries) > 0 {
 		for _, kvp := range queries[0] {
+			if strings.HasPrefix(kvp.key, "-") && kvp.value == "" {
+				flags = append(flags, kvp.key)
+				continue
+			}
 			params.Add(kvp.key, kvp.value)
 		}
 	}
+
+	// Flags always go in the URL, parameters go in the body of a POST request
+	var payload io.Reader
+	method := http.MethodGet
+	if post {
+		values := make(map[string]string)
+		for k := range params {
+			values[k] = params.Get(k)
+		}
+
+		data, err := json.Marshal(values)
+		if err != nil {
+			Log(Error, "Failed to encode request body \n%v", err)
+			return nil, "", err
+		}
+
+		payload = bytes.NewReader(data)
+		method = http.MethodPost
+		params = url.Values{}
+	}
+
 	baseUrl.RawQuery = params.Encode()
+	if len(flags) > 0 {
+		baseUrl.RawQuery = strings.TrimLeft(baseUrl.RawQuery+"&"+strings.Join(flags, "&"), "&")
+	}
 
-	req, err := http.NewRequest("GET", baseUrl.String(), nil)
+	req, err := http.NewRequest(method, baseUrl.String(), payload)
 	if err != nil {
 		Log(Error, "Error building an HTTP request \n%v", err)
 		return nil, "", err
 	}
+	if post {
+		req.Header.Set("Content-Type", "application/json")
+	}
 
-	// Exectue the request
-	res, err := doRequest(req, err, &http.Client{})
-	if err != nil {
-		Log(Error, "Error executing HTTP request \n%v", err)
-		return nil, "", err
+	// Exectue the request through the middleware chain
+	res := handler()(&Request{Command: path, VirtualServer: sid, ApiKey: apiKey, Http: req})
+	if res.Err != nil {
+		Log(Error, "Error executing HTTP request \n%v", res.Err)
+		return nil, "", res.Err
 	}
 
-	var r Response
-	json.Unmarshal(res, &r)
-	return &r.Status, string(r.Body), err
+	return res.Status, res.Body, nil
 }
 
-// Exectue HTTP Request
-func doRequest(req *http.Request, err error, client *http.Client) ([]byte, error) {
-	req.Header.Set("x-api-key", api_)
+// The innermost handler, sends the request and decodes the response
+func transport(r *Request) *Result {
+	start := time.Now()
+	r.Http.Header.Set("x-api-key", r.ApiKey)
+	code, data, err := doRequest(r.Http, &http.Client{})
+	// Network errors include the URL, which must not leak tokens or passwords into the logs
+	if uerr, ok := err.(*url.Error); ok {
+		uerr.URL = redactUrl(r.Http.URL)
+	}
+	res := &Result{HttpStatus: code, Duration: time.Since(start), Err: err}
+	if err == nil {
+		var body Response
+		json.Unmarshal(data, &body)
+		res.Status = &body.Status
+		res.Body = string(body.Body)
+	}
 
-	Log(CmdExc, "%v", req.URL)
+	logRequest(r, res)
+	return res
+}
+
+// Exectue HTTP Request
+func doRequest(req *http.Request, client *http.Client) (int, []byte, error) {
 	resp, err := client.Do(req)
 
 	if err != nil {
-		return nil, err
+		return 0, nil, err
 	}
 
 	defer resp.Body.Close()
-	return ioutil.ReadAll(resp.Body)
+	data, err := ioutil.ReadAll(resp.Body)
+	return resp.StatusCode, data, err
 }
@@ -3,26 +3,61 @@ package ts3
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 )
 
+// Maximum number of CLDBIDs sent in a single clientdbinfo request, keeps the URL to a sensible length
+const dbInfoBatchSize = 500
+
 type User struct {
 	// Database ID
 	Cldbid int64 `json:"client_database_id,string"`
 	// Unique TS ID
 	Cluid string `json:"client_unique_identifier"`
-	// Last Connection
+	// Unix timestamp of the first connection
+	Created int64 `json:"client_created,string"`
+	// Last Connection, a unix timestamp, use LastConnectedUnix to read it as a number
 	LastConnected string `json:"client_lastconnected"`
 	LastIP        string `json:"client_lastip"`
 
 	ActiveSessionIds []int64
 	Nickname         string `json:"client_nickname"`
+	Description      string `json:"client_description"`
+
+	// Connection and traffic counters
+	TotalConnections     int64 `json:"client_totalconnections,string"`
+	MonthBytesUploaded   int64 `json:"client_month_bytes_uploaded,string"`
+	MonthBytesDownloaded int64 `json:"client_month_bytes_downloaded,string"`
+	TotalBytesUploaded   int64 `json:"client_total_bytes_uploaded,string"`
+	TotalBytesDownloaded int64 `json:"client_total_bytes_downloaded,string"`
 }
 
-type Session struct {
-	Cldbid   int64  `json:"client_database_id,string"`
+// The last connection as a unix timestamp, 0 if the user has never connected
+func (u User) LastConnectedUnix() int64 {
+	ts, _ := strconv.ParseInt(u.LastConnected, 10, 64)
+	return ts
+}
+
+// The identifiers returned by the clientgetids command
+type ClientId struct {
+	Cluid    string `json:"cluid"`
 	Clid     int64  `json:"clid,string"`
-	Nickname string `json:"client_nickname"`
+	Nickname string `json:"name"`
+}
+
+type Session struct {
+	Cldbid    int64  `json:"client_database_id,string"`
+	Clid      int64  `json:"clid,string"`
+	ChannelId int64  `json:"cid,string"`
+	Nickname  string `json:"client_nickname"`
+	// 0 for voice clients, 1 for query clients
+	Type int64 `json:"client_type,string"`
+	// Only set by functions that request the -groups flag
+	ServerGroups   IdList `json:"client_servergroups"`
+	ChannelGroupId int64  `json:"client_channel_group_id,string"`
+	// Only set by functions that request the -voice flag
+	IsTalker Flag `json:"client_is_talker"`
 }
 
 // Returns a map of active sessions mapped to their database IDs
@@ -62,6 +97,281 @@ func UserFindByDbId(cldbid int64) (*status, *User, error) {
 	return qres, &user[0], err
 }
 
+// List users in the client database. Start is the offset into the database and
+// duration is the number of users to return
+func UserList(start int64, duration int64) (*status, []User, error) {
+	queries := []KeyValue{
+		{key: "start", value: i64tostr(start)},
+		{key: "duration", value: i64tostr(duration)},
+	}
+
+	qres, body, err := get("clientdblist", false, queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to list the client database {start: %v, duration: %v} \n%v\n%v", start, duration, qres, err)
+		return qres, nil, err
+	}
+
+	var users []User
+	json.Unmarshal([]byte(body), &users)
+
+	// clientdblist uses 'cldbid' instead of 'client_database_id'
+	type cldbid_ struct {
+		Clid int64 `json:"cldbid,string"`
+	}
+
+	var ids []cldbid_
+	json.Unmarshal([]byte(body), &ids)
+	for i := range users {
+		users[i].Cldbid = ids[i].Clid
+	}
+
+	return qres, users, err
+}
+
+// Count the number of users in the client database
+func UserCount() (*status, int64, error) {
+	queries := []KeyValue{
+		{key: "start", value: "0"},
+		{key: "duration", value: "1"},
+		{key: "-count", value: ""},
+	}
+
+	qres, body, err := get("clientdblist", false, queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to count the client database \n%v\n%v", qres, err)
+		return qres, -1, err
+	}
+
+	type count_ struct {
+		Count int64 `json:"count,string"`
+	}
+
+	var count []count_
+	json.Unmarshal([]byte(body), &count)
+	if len(count) == 0 {
+		return qres, 0, err
+	}
+
+	return qres, count[0].Count, err
+}
+
+// Search the client database for users with a nickname matching pattern. Use % as a wildcard
+func UserFindByName(pattern string) (*status, []User, error) {
+	return userDbFind(pattern, false)
+}
+
+// Search the client database for users with the unique identifier matching pattern
+func UserFindByUid(pattern string) (*status, []User, error) {
+	return userDbFind(pattern, true)
+}
+
+// Run clientdbfind and look up the matching users
+func userDbFind(pattern string, uid bool) (*status, []User, error) {
+	queries := []KeyValue{
+		{key: "pattern", value: pattern},
+	}
+	if uid {
+		queries = append(queries, KeyValue{key: "-uid", value: ""})
+	}
+
+	qres, body, err := get("clientdbfind", false, queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to search the client database {pattern: %v, uid: %v} \n%v\n%v", pattern, uid, qres, err)
+		return qres, nil, err
+	}
+
+	type cldbid_ struct {
+		Clid int64 `json:"cldbid,string"`
+	}
+
+	var u []cldbid_
+	json.Unmarshal([]byte(body), &u)
+
+	cldbids := []int64{}
+	for _, id := range u {
+		cldbids = append(cldbids, id.Clid)
+	}
+	if len(cldbids) == 0 {
+		return qres, []User{}, err
+	}
+
+	return UsersFindByDbIds(cldbids)
+}
+
+// List the connected clients (CLIDs) using a unique identifier
+func UserClientIds(cluid string) (*status, []ClientId, error) {
+	queries := []KeyValue{
+		{key: "cluid", value: cluid},
+	}
+
+	qres, body, err := get("clientgetids", false, queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to get the clients for CLUID %v \n%v\n%v", cluid, qres, err)
+		return qres, nil, err
+	}
+
+	var ids []ClientId
+	json.Unmarshal([]byte(body), &ids)
+	return qres, ids, err
+}
+
+// Get the CLDBID belonging to a unique identifier
+func UserDbIdFromUid(cluid string) (*status, int64, error) {
+	queries := []KeyValue{
+		{key: "cluid", value: cluid},
+	}
+
+	qres, body, err := get("clientgetdbidfromuid", false, queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to get the CLDBID for CLUID %v \n%v\n%v", cluid, qres, err)
+		return qres, -1, err
+	}
+
+	type cldbid_ struct {
+		Clid int64 `json:"cldbid,string"`
+	}
+
+	var u []cldbid_
+	json.Unmarshal([]byte(body), &u)
+	if len(u) == 0 {
+		return qres, -1, err
+	}
+
+	return qres, u[0].Clid, err
+}
+
+// Get the last known nickname belonging to a unique identifier
+func UserNameFromUid(cluid string) (*status, string, error) {
+	queries := []KeyValue{
+		{key: "cluid", value: cluid},
+	}
+
+	qres, body, err := get("clientgetnamefromuid", false, queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to get the nickname for CLUID %v \n%v\n%v", cluid, qres, err)
+		return qres, "", err
+	}
+
+	return qres, decodeName(body), err
+}
+
+// Get the last known nickname belonging to a CLDBID
+func UserNameFromDbId(cldbid int64) (*status, string, error) {
+	queries := []KeyValue{
+		{key: "cldbid", value: i64tostr(cldbid)},
+	}
+
+	qres, body, err := get("clientgetnamefromdbid", false, queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to get the nickname for CLDBID %v \n%v\n%v", cldbid, qres, err)
+		return qres, "", err
+	}
+
+	return qres, decodeName(body), err
+}
+
+// Read the name from a clientgetnamefrom* response
+func decodeName(body string) string {
+	type name_ struct {
+		Name string `json:"name"`
+	}
+
+	var n []name_
+	json.Unmarshal([]byte(body), &n)
+	if len(n) == 0 {
+		return ""
+	}
+
+	return n[0].Name
+}
+
+// Look up several users in as few requests as possible by sending their CLDBIDs to clientdbinfo in batches
+// Users that don't exist are left out of the result
+func UsersFindByDbIds(cldbids []int64) (*status, []User, error) {
+	var qres *status
+	users := []User{}
+
+	for start := 0; start < len(cldbids); start += dbInfoBatchSize {
+		end := start + dbInfoBatchSize
+		if end > len(cldbids) {
+			end = len(cldbids)
+		}
+
+		queries := []KeyValue{}
+		for _, cldbid := range cldbids[start:end] {
+			queries = append(queries, KeyValue{key: "cldbid", value: i64tostr(cldbid)})
+		}
+
+		var body string
+		var err error
+		qres, body, err = get("clientdbinfo", false, queries)
+		if err != nil || !qres.IsSuccess() {
+			Log(Error, "Failed to get information for %v CLDBIDs \n%v\n%v", end-start, qres, err)
+			return qres, nil, err
+		}
+
+		var batch []User
+		json.Unmarshal([]byte(body), &batch)
+		users = append(users, batch...)
+	}
+
+	return qres, users, nil
+}
+
+// Options for the group member lookups
+type MemberOptions struct {
+	// Don't look up the members' connected clients, ActiveSessionIds will be empty
+	SkipSessions bool
+	// Don't look up the members' client database entries, only the CLDBID
+	// (and for server groups the nickname and unique ID) will be set
+	SkipDetails bool
+}
+
+// Fill in the details and active sessions of group members in a constant number of requests
+func resolveMembers(members []User, opts MemberOptions) (*status, []User, error) {
+	var qres *status
+
+	if !opts.SkipDetails && len(members) > 0 {
+		cldbids := []int64{}
+		for _, member := range members {
+			cldbids = append(cldbids, member.Cldbid)
+		}
+
+		var details []User
+		var err error
+		qres, details, err = UsersFindByDbIds(cldbids)
+		if err != nil || !qres.IsSuccess() {
+			return qres, nil, err
+		}
+
+		byId := make(map[int64]User)
+		for _, u := range details {
+			byId[u.Cldbid] = u
+		}
+
+		for i, member := range members {
+			if u, ok := byId[member.Cldbid]; ok {
+				members[i] = u
+			}
+		}
+	}
+
+	if !opts.SkipSessions {
+		var sessions map[int64][]int64
+		var err error
+		qres, sessions, err = ActiveClients()
+		if err != nil || !qres.IsSuccess() {
+			return qres, nil, err
+		}
+
+		for i := range members {
+			members[i].ActiveSessionIds = sessions[members[i].Cldbid]
+		}
+	}
+
+	return qres, members, nil
+}
+
 // Find a user using the custom field sets that were attached to their privilege token
 // You can only search one column/ident and value at a time.
 func UserFindByCustomSearch(ident string, pattern string) (*status, *User, error) {
@@ -118,6 +428,53 @@ func UserPoke(clid int64, msg string) (*status, error) {
 	return qres, err
 }
 
+// Send a private text message to a client
+func UserMessage(clid int64, msg string) (*status, error) {
+	queries := []KeyValue{
+		{key: "targetmode", value: "1"},
+		{key: "target", value: i64tostr(clid)},
+		{key: "msg", value: msg},
+	}
+
+	qres, _, err := get("sendtextmessage", false, queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to message CLID %v \n%v\n%v", clid, qres, err)
+	}
+
+	return qres, err
+}
+
+// Kick a client from the server
+func UserKick(clid int64, msg string) (*status, error) {
+	queries := []KeyValue{
+		{key: "clid", value: i64tostr(clid)},
+		{key: "reasonid", value: "5"},
+		{key: "reasonmsg", value: msg},
+	}
+
+	qres, _, err := get("clientkick", false, queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to kick CLID %v \n%v\n%v", clid, qres, err)
+	}
+
+	return qres, err
+}
+
+// Move a client into a channel
+func UserMove(clid int64, cid int64) (*status, error) {
+	queries := []KeyValue{
+		{key: "clid", value: i64tostr(clid)},
+		{key: "cid", value: i64tostr(cid)},
+	}
+
+	qres, _, err := get("clientmove", false, queries)
+	if err != nil || !qres.IsSuccess() {
+		Log(Error, "Failed to move CLID %v to channel %v \n%v\n%v", clid, cid, qres, err)
+	}
+
+	return qres, err
+}
+
 // Delete a user from the user database. This will revoke all of their permissions
 // and can be used to clear a users custom fields
 func UserDelete(cldbid int64) (*status, error) {
@@ -150,16 +507,9 @@ func UserKickClients(cldbid int64, msg string) (*status, error) {
 	failed := 0
 
 	for _, clid := range sessions[cldbid] {
-		queries := []KeyValue{
-			{key: "clid", value: i64tostr(clid)},
-			{key: "reasonid", value: "5"},
-			{key: "reasonmsg", value: msg},
-		}
-
-		qres1, _, err := get("clientkick", false, queries)
-		if err != nil || !qres.IsSuccess() {
+		qres1, err := UserKick(clid, msg)
+		if err != nil || !qres1.IsSuccess() {
 			failed++
-			Log(Error, "Failed to kick CLID %v \n%v\n%v", clid, qres1, err)
 		}
 	}
 
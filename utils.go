@@ -1,6 +1,7 @@
 package ts3
 
 import (
+	"encoding/json"
 	"strconv"
 	"strings"
 )
@@ -36,3 +37,66 @@ func WebEncode(s string) string {
 func i64tostr(i int64) string {
 	return strconv.FormatInt(i, 10)
 }
+
+// Flag is a boolean sent by the server as "0" or "1"
+type Flag bool
+
+func (f *Flag) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	*f = s == "1"
+	return nil
+}
+
+// Converts a bool to the "0" or "1" the server expects
+func booltostr(b bool) string {
+	if b {
+		return "1"
+	}
+
+	return "0"
+}
+
+// Returns a pointer to s, used to set optional string properties
+func String(s string) *string {
+	return &s
+}
+
+// Returns a pointer to i, used to set optional numeric properties
+func Int64(i int64) *int64 {
+	return &i
+}
+
+// Returns a pointer to b, used to set optional boolean properties
+func Bool(b bool) *bool {
+	return &b
+}
+
+// IdList is a comma separated list of IDs such as client_servergroups
+type IdList []int64
+
+func (l *IdList) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	ids := IdList{}
+	for _, part := range strings.Split(s, ",") {
+		if part == "" {
+			continue
+		}
+
+		id, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	}
+
+	*l = ids
+	return nil
+}